@@ -99,6 +99,13 @@ func benchmarkGo(b *testing.B, tasks []Task) {
 		fs := setupFuncs(tasks)
 		runGroupGo(b, group.Opts(group.WithTimeout(1*time.Minute), group.WithLog), fs...)
 	})
+
+	b.Run("GroupGoWithPool", func(b *testing.B) {
+		fs := setupFuncs(tasks)
+		pool := group.NewPool(len(fs), 0, time.Second)
+		defer pool.Close()
+		runGroupGo(b, group.Opts(group.WithPool(pool)), fs...)
+	})
 }
 
 func runStdGoroutine(b *testing.B, fs ...func() error) {
@@ -0,0 +1,64 @@
+package group
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is the time source of the group timeout, e.g. grouptest.FakeClock
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f after d, stop prevents f from being called
+	AfterFunc(d time.Duration, f func()) (stop func() bool)
+}
+
+// returns a ctx cancelled with context.DeadlineExceeded after d on clock
+func timeoutContext(ctx context.Context, clock Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if clock == nil {
+		return context.WithTimeout(ctx, d)
+	}
+	c := &clockCtx{Context: ctx, deadline: clock.Now().Add(d), done: make(chan token)}
+	stopTimer := clock.AfterFunc(d, func() { c.cancel(context.DeadlineExceeded) })
+	stopParent := context.AfterFunc(ctx, func() { c.cancel(ctx.Err()) })
+	return c, func() {
+		stopTimer()
+		stopParent()
+		c.cancel(context.Canceled)
+	}
+}
+
+// reports whether the deadline of ctx is past though its timer may not have fired yet, a Clock deadline is left to its timer
+func expired(ctx context.Context, clock Clock) bool {
+	d, ok := ctx.Deadline()
+	return ok && clock == nil && !time.Now().Before(d)
+}
+
+// ctx with deadline driven by Clock
+// it owns the done channel, so the derived ctxs get its err instead of the parent's
+type clockCtx struct {
+	context.Context
+	deadline time.Time
+	done     chan token
+
+	mu  sync.Mutex
+	err error
+}
+
+func (c *clockCtx) Deadline() (time.Time, bool) { return c.deadline, true }
+func (c *clockCtx) Done() <-chan struct{}       { return c.done }
+
+func (c *clockCtx) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *clockCtx) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+		close(c.done)
+	}
+}
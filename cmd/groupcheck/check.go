@@ -0,0 +1,385 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const groupPath = "github.com/oatcatx/group"
+
+type diag struct {
+	pos token.Position
+	msg string
+}
+
+func (d diag) String() string {
+	name := d.pos.Filename
+	if wd, err := filepath.Abs("."); err == nil {
+		if rel, err := filepath.Rel(wd, name); err == nil && !strings.HasPrefix(rel, "..") {
+			name = rel
+		}
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", name, d.pos.Line, d.pos.Column, d.msg)
+}
+
+// runner registered in the Options
+type entry struct {
+	name    string    // empty for anonymous runner
+	namePos token.Pos // position of the name arg
+	deps    []dep
+}
+
+type dep struct {
+	name string
+	pos  token.Pos
+	soft bool // SoftDep, may be missing
+}
+
+// dependency graph of an Options value
+type graph struct {
+	runners map[any]*entry // runner id -> entry
+	order   []any          // runner ids in source order
+	dynamic bool           // has non-constant names
+}
+
+// Options variable as assigned at pos, a reassigned variable holds a new graph
+type optsKey struct {
+	obj types.Object
+	pos token.Pos
+}
+
+type checker struct {
+	fset    *token.FileSet
+	pkg     *types.Package
+	info    *types.Info
+	graphs  map[any]*graph               // Options key (or expr) -> graph
+	keys    []any                        // Options in source order
+	assigns map[types.Object][]token.Pos // variable -> assignment positions in source order
+	vars    map[types.Object]any         // runner variable -> runner id
+}
+
+func check(fset *token.FileSet, files []*ast.File) []diag {
+	c := &checker{fset: fset, graphs: make(map[any]*graph), assigns: make(map[types.Object][]token.Pos), vars: make(map[types.Object]any)}
+	c.pkg, c.info = typeCheck(fset, files)
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if as, ok := n.(*ast.AssignStmt); ok {
+				for _, lhs := range as.Lhs {
+					if obj := c.obj(lhs); obj != nil {
+						c.assigns[obj] = append(c.assigns[obj], as.Pos())
+					}
+				}
+			}
+			return true
+		})
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			seen := make(map[*ast.CallExpr]bool)
+			ast.Inspect(decl, func(n ast.Node) bool {
+				switch x := n.(type) {
+				case *ast.AssignStmt:
+					if len(x.Lhs) == len(x.Rhs) {
+						for i, rhs := range x.Rhs {
+							c.bind(decl, x.Lhs[i], rhs, seen)
+						}
+					}
+				case *ast.ValueSpec:
+					if len(x.Names) == len(x.Values) {
+						for i, rhs := range x.Values {
+							c.bind(decl, x.Names[i], rhs, seen)
+						}
+					}
+				case *ast.CallExpr:
+					if !seen[x] {
+						c.chain(decl, x, seen)
+					}
+				}
+				return true
+			})
+		}
+	}
+	var diags []diag
+	for _, k := range c.keys {
+		diags = append(diags, c.verify(c.graphs[k])...)
+	}
+	slices.SortStableFunc(diags, func(a, b diag) int {
+		if a.pos.Filename != b.pos.Filename {
+			return strings.Compare(a.pos.Filename, b.pos.Filename)
+		}
+		return a.pos.Offset - b.pos.Offset
+	})
+	return diags
+}
+
+// records the runner chain assigned to a variable, so later calls on the variable apply to the same runner
+func (c *checker) bind(decl ast.Decl, lhs, rhs ast.Expr, seen map[*ast.CallExpr]bool) {
+	call, ok := ast.Unparen(rhs).(*ast.CallExpr)
+	if !ok || seen[call] {
+		return
+	}
+	if id := c.chain(decl, call, seen); id != nil {
+		if obj := c.obj(lhs); obj != nil {
+			c.vars[obj] = id
+		}
+	}
+}
+
+// records the runner chain ending with call, e.g. MakeRunner(f).Name(opts, "a").Dep(opts, "b"), and returns the runner id
+func (c *checker) chain(decl ast.Decl, call *ast.CallExpr, seen map[*ast.CallExpr]bool) any {
+	var calls []*ast.CallExpr // outermost first
+	var root ast.Expr
+	for x := ast.Expr(call); ; {
+		cx, ok := ast.Unparen(x).(*ast.CallExpr)
+		if !ok {
+			root = x
+			break
+		}
+		sel, ok := ast.Unparen(cx.Fun).(*ast.SelectorExpr)
+		if !ok || !c.isRunnerMethod(sel) {
+			switch c.makeRunner(cx) {
+			case "MakeRunner":
+				if len(cx.Args) == 1 {
+					root = cx.Args[0]
+				}
+			case "MakeContextRunner", "MakeGroupRunner":
+				root = cx // made per call
+			}
+			break
+		}
+		seen[cx], calls, x = true, append(calls, cx), sel.X
+	}
+	if root == nil || len(calls) == 0 {
+		return nil
+	}
+	id := c.runner(decl, root)
+	for _, cx := range slices.Backward(calls) {
+		method := ast.Unparen(cx.Fun).(*ast.SelectorExpr).Sel.Name
+		if len(cx.Args) == 0 {
+			continue
+		}
+		g := c.graph(decl, cx.Args[0])
+		e := g.runners[id]
+		switch method {
+		case "Name":
+			if e != nil && e.name != "" || len(cx.Args) != 2 {
+				continue // Name is ignored for named runners
+			}
+			name, ok := c.str(cx.Args[1])
+			if !ok {
+				g.dynamic = true
+				continue
+			}
+			if e == nil {
+				e = &entry{}
+				g.runners[id], g.order = e, append(g.order, id)
+			}
+			e.name, e.namePos = name, cx.Args[1].Pos()
+		case "Dep", "DepTolerant", "SoftDep", "DepAny", "DepQuorum":
+			if e == nil {
+				e = &entry{}
+				g.runners[id], g.order = e, append(g.order, id)
+			}
+			args := cx.Args[1:]
+			if method == "DepQuorum" {
+				args = args[min(1, len(args)):] // k
+			}
+			for _, arg := range args {
+				if _, ok := arg.(*ast.Ellipsis); ok {
+					g.dynamic = true
+					continue
+				}
+				if name, ok := c.str(arg); !ok {
+					g.dynamic = true
+				} else if name != "" {
+					e.deps = append(e.deps, dep{name: name, pos: arg.Pos(), soft: method == "SoftDep"})
+				}
+			}
+			if cx.Ellipsis.IsValid() {
+				g.dynamic = true
+			}
+		case "Tolerant", "Finally", "Compensate":
+			if e == nil {
+				g.runners[id], g.order = &entry{}, append(g.order, id)
+			}
+		}
+	}
+	return id
+}
+
+// returns the id of the runner made of root: a func literal or a runner made per call is identified by its position,
+// a named func by its object (the same func is the same runner), a runner variable by the chain assigned to it
+func (c *checker) runner(decl ast.Decl, root ast.Expr) any {
+	switch x := ast.Unparen(root).(type) {
+	case *ast.FuncLit, *ast.CallExpr:
+		return x.Pos()
+	}
+	if obj := c.obj(root); obj != nil {
+		if id, ok := c.vars[obj]; ok {
+			return id
+		}
+		return obj
+	}
+	return fmt.Sprintf("%d:%s", decl.Pos(), types.ExprString(root))
+}
+
+// returns the object referred to by the ident or selector expr
+func (c *checker) obj(expr ast.Expr) types.Object {
+	var id *ast.Ident
+	switch x := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		id = x
+	case *ast.SelectorExpr:
+		id = x.Sel
+	default:
+		return nil
+	}
+	if obj := c.info.Uses[id]; obj != nil {
+		return obj
+	}
+	return c.info.Defs[id]
+}
+
+// returns the graph of the Options expr
+func (c *checker) graph(decl ast.Decl, opts ast.Expr) *graph {
+	var key any = fmt.Sprintf("%d:%s", decl.Pos(), types.ExprString(opts))
+	if obj := c.obj(opts); obj != nil {
+		// the last assignment before the use, ignoring control flow
+		k := optsKey{obj: obj}
+		for _, pos := range c.assigns[obj] {
+			if pos < opts.Pos() {
+				k.pos = pos
+			}
+		}
+		key = k
+	}
+	if c.graphs[key] == nil {
+		c.graphs[key], c.keys = &graph{runners: make(map[any]*entry)}, append(c.keys, key)
+	}
+	return c.graphs[key]
+}
+
+func (c *checker) isRunnerMethod(sel *ast.SelectorExpr) bool {
+	switch sel.Sel.Name {
+	case "Name", "Dep", "DepTolerant", "SoftDep", "DepAny", "DepQuorum", "Tolerant", "Finally", "Compensate", "Verify":
+	default:
+		return false
+	}
+	if fn, ok := c.info.Uses[sel.Sel].(*types.Func); ok {
+		return c.isGroup(fn.Pkg())
+	}
+	return true // unresolved, syntactic match
+}
+
+// returns the name of the runner constructor called, "" if not one
+func (c *checker) makeRunner(call *ast.CallExpr) string {
+	var id *ast.Ident
+	switch x := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = x
+	case *ast.SelectorExpr:
+		id = x.Sel
+	default:
+		return ""
+	}
+	switch id.Name {
+	case "MakeRunner", "MakeContextRunner", "MakeGroupRunner":
+	default:
+		return ""
+	}
+	if fn, ok := c.info.Uses[id].(*types.Func); ok && !c.isGroup(fn.Pkg()) {
+		return ""
+	}
+	return id.Name // resolved, or unresolved syntactic match
+}
+
+// reports whether pkg is the group package, which is checked by its name when checking itself
+func (c *checker) isGroup(pkg *types.Package) bool {
+	return pkg != nil && (pkg.Path() == groupPath || pkg == c.pkg && pkg.Name() == "group")
+}
+
+// returns the constant string value of expr
+func (c *checker) str(expr ast.Expr) (string, bool) {
+	if tv, ok := c.info.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constant.StringVal(tv.Value), true
+	}
+	if lit, ok := ast.Unparen(expr).(*ast.BasicLit); ok && lit.Kind == token.STRING {
+		s, err := strconv.Unquote(lit.Value)
+		return s, err == nil
+	}
+	return "", false
+}
+
+// reports duplicates, missing deps and cycles
+func (c *checker) verify(g *graph) []diag {
+	var diags []diag
+	report := func(pos token.Pos, format string, args ...any) {
+		diags = append(diags, diag{pos: c.fset.Position(pos), msg: fmt.Sprintf(format, args...)})
+	}
+
+	named := make(map[string]*entry)
+	for _, id := range g.order {
+		e := g.runners[id]
+		if e.name == "" {
+			continue
+		}
+		if first, ok := named[e.name]; ok {
+			report(e.namePos, "duplicate dependency source %q, first declared at %s", e.name, c.fset.Position(first.namePos))
+			continue
+		}
+		named[e.name] = e
+	}
+	for _, id := range g.order {
+		e := g.runners[id]
+		for _, d := range e.deps {
+			if _, ok := named[d.name]; !ok && !g.dynamic && !d.soft {
+				report(d.pos, "missing dependency %s -> %q", cond(e.name != "", strconv.Quote(e.name), "anonymous runner"), d.name)
+			}
+			if d.name == e.name {
+				report(d.pos, "self dependency %q", e.name)
+			}
+		}
+	}
+
+	// cycle dfs, each cycle is reported once on its first runner
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int)
+	var path []string
+	var dfs func(name string)
+	dfs = func(name string) {
+		state[name], path = visiting, append(path, name)
+		for _, d := range named[name].deps {
+			if _, ok := named[d.name]; !ok || d.name == name {
+				continue
+			}
+			switch state[d.name] {
+			case visiting:
+				cycle := append(slices.Clone(path[slices.Index(path, d.name):]), d.name)
+				q := make([]string, len(cycle))
+				for i, x := range cycle {
+					q[i] = strconv.Quote(x)
+				}
+				report(named[d.name].namePos, "dependency cycle detected: %s", strings.Join(q, " -> "))
+			case 0:
+				dfs(d.name)
+			}
+		}
+		state[name], path = visited, path[:len(path)-1]
+	}
+	for _, id := range g.order {
+		if e := g.runners[id]; e.name != "" && named[e.name] == e && state[e.name] == 0 {
+			dfs(e.name)
+		}
+	}
+	return diags
+}
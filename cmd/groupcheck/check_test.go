@@ -0,0 +1,59 @@
+package main
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var wantRe = regexp.MustCompile("// want `([^`]*)`")
+
+// checks the testdata packages, each diagnostic must match the want comment on its line
+func TestCheck(t *testing.T) {
+	t.Parallel()
+	dirs, err := filepath.Glob("testdata/src/*")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, dirs)
+	for _, dir := range dirs {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			fset := token.NewFileSet()
+			pkgs, err := parseDir(fset, dir, true)
+			assert.Nil(t, err)
+
+			wants := make(map[string]*regexp.Regexp) // file:line -> want
+			for _, files := range pkgs {
+				for _, f := range files {
+					name := fset.Position(f.Pos()).Filename
+					src, err := os.ReadFile(name)
+					assert.Nil(t, err)
+					for i, line := range strings.Split(string(src), "\n") {
+						if m := wantRe.FindStringSubmatch(line); m != nil {
+							wants[name+":"+strconv.Itoa(i+1)] = regexp.MustCompile(m[1])
+						}
+					}
+				}
+			}
+
+			for _, files := range pkgs {
+				for _, d := range check(fset, files) {
+					key := d.pos.Filename + ":" + strconv.Itoa(d.pos.Line)
+					want, ok := wants[key]
+					if !assert.True(t, ok, "unexpected diagnostic %s", d) {
+						continue
+					}
+					assert.Regexp(t, want, d.msg)
+					delete(wants, key)
+				}
+			}
+			for key, want := range wants {
+				t.Errorf("%s: no diagnostic matching %q", key, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Command groupcheck reports the broken Name/Dep chains of group runners at build time
+//
+//	groupcheck [-tests] [packages]
+//
+// runners sharing the same Options variable (until it is reassigned) are checked for missing deps, duplicate names and cycles, e.g.
+//
+//	var opts = group.Opts(group.WithDep)
+//	group.Go(ctx, opts,
+//		group.MakeRunner(a).Name(opts, "a"),
+//		group.MakeRunner(b).Name(opts, "b").Dep(opts, "c")) // main.go:12:52: missing dependency "b" -> "c"
+//
+// only constant names are checked, the Options with dynamic names are skipped for missing deps
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+func main() {
+	tests := flag.Bool("tests", false, "check test files")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: groupcheck [-tests] [packages]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	dirs, err := expand(patterns)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "groupcheck:", err)
+		os.Exit(2)
+	}
+	var diags []diag
+	fset := token.NewFileSet()
+	for _, dir := range dirs {
+		pkgs, err := parseDir(fset, dir, *tests)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "groupcheck:", err)
+			os.Exit(2)
+		}
+		for _, files := range pkgs {
+			diags = append(diags, check(fset, files)...)
+		}
+	}
+	for _, d := range diags {
+		fmt.Println(d)
+	}
+	if len(diags) > 0 {
+		os.Exit(1)
+	}
+}
+
+// expands the package patterns to dirs, "dir/..." for all dirs under dir
+func expand(patterns []string) ([]string, error) {
+	var dirs []string
+	for _, p := range patterns {
+		root, ok := strings.CutSuffix(p, "...")
+		if !ok {
+			dirs = append(dirs, p)
+			continue
+		}
+		root = filepath.Clean(cond(root == "", ".", root))
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return err
+			}
+			if name := d.Name(); path != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, path)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return slices.Compact(dirs), nil
+}
+
+// parses the go files in dir, grouped by package name
+func parseDir(fset *token.FileSet, dir string, tests bool) (map[string][]*ast.File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	pkgs := make(map[string][]*ast.File)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || !tests && strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		pkgs[f.Name.Name] = append(pkgs[f.Name.Name], f)
+	}
+	return pkgs, nil
+}
+
+// type checks the files leniently, the info is partial if the imports can't be resolved
+func typeCheck(fset *token.FileSet, files []*ast.File) (*types.Package, *types.Info) {
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{Importer: importer.Default(), Error: func(error) {}}
+	pkg, _ := conf.Check(files[0].Name.Name, fset, files, info)
+	return pkg, info
+}
+
+func cond[T any](cond bool, t, f T) T {
+	if cond {
+		return t
+	}
+	return f
+}
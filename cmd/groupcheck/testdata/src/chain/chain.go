@@ -0,0 +1,80 @@
+package chain
+
+import (
+	"context"
+
+	"github.com/oatcatx/group"
+)
+
+func f() error { return nil }
+
+// inline func literals are distinct runners
+func inline(ctx context.Context) error {
+	var opts = group.Opts(group.WithDep)
+	return group.Go(ctx, opts,
+		group.MakeRunner(func() error { return nil }).Name(opts, "a"),
+		group.MakeRunner(func() error { return nil }).Name(opts, "b").Dep(opts, "a"),
+		group.MakeContextRunner(opts, func(context.Context) error { return nil }).Name(opts, "c").Dep(opts, "a", "b"),
+		group.MakeContextRunner(opts, func(context.Context) error { return nil }).Name(opts, "d").Dep(opts, "c"))
+}
+
+// a reassigned Options holds a new graph
+func reassigned(ctx context.Context) error {
+	var opts = group.Opts(group.WithDep)
+	if err := group.Go(ctx, opts,
+		group.MakeRunner(func() error { return nil }).Name(opts, "a"),
+		group.MakeRunner(func() error { return nil }).Name(opts, "b").Dep(opts, "a")); err != nil {
+		return err
+	}
+	opts = group.Opts(group.WithDep)
+	return group.Go(ctx, opts,
+		group.MakeRunner(func() error { return nil }).Name(opts, "a"),
+		group.MakeRunner(func() error { return nil }).Name(opts, "c").Dep(opts, "b")) // want `missing dependency "c" -> "b"`
+}
+
+// the same func is the same runner
+func same() {
+	var opts = group.Opts(group.WithDep)
+	group.MakeRunner(f).Name(opts, "a")
+	group.MakeRunner(f).Name(opts, "b")
+	group.MakeRunner(func() error { return nil }).Dep(opts, "b") // want `missing dependency anonymous runner -> "b"`
+}
+
+// named after Finally
+func finally() {
+	var opts = group.Opts(group.WithDep)
+	group.MakeRunner(func() error { return nil }).Name(opts, "a")
+	group.MakeRunner(func() error { return nil }).Finally(opts).Name(opts, "cleanup").Dep(opts, "a")
+	group.MakeRunner(func() error { return nil }).Tolerant(opts).Name(opts, "b").Dep(opts, "cleanup")
+	group.MakeRunner(func() error { return nil }).Dep(opts, "b").Name(opts, "c").Dep(opts, "x") // want `missing dependency "c" -> "x"`
+}
+
+// calls on a runner variable apply to the runner assigned
+func variable(dep bool) {
+	var opts = group.Opts(group.WithDep)
+	group.MakeRunner(func() error { return nil }).Name(opts, "a")
+	b := group.MakeRunner(func() error { return nil }).Name(opts, "b")
+	if dep {
+		b.Dep(opts, "a")
+		b.Dep(opts, "x") // want `missing dependency "b" -> "x"`
+	}
+}
+
+func diagnostics() {
+	var opts = group.Opts(group.WithDep)
+	group.MakeRunner(func() error { return nil }).Name(opts, "a")
+	group.MakeRunner(func() error { return nil }).Name(opts, "a")                // want `duplicate dependency source "a", first declared at .*chain.go:\d+:\d+`
+	group.MakeRunner(func() error { return nil }).Name(opts, "b").Dep(opts, "b") // want `self dependency "b"`
+	group.MakeRunner(func() error { return nil }).Name(opts, "c").Dep(opts, "d") // want `dependency cycle detected: "c" -> "d" -> "c"`
+	group.MakeRunner(func() error { return nil }).Name(opts, "d").Dep(opts, "c")
+	group.MakeRunner(func() error { return nil }).Name(opts, "e").SoftDep(opts, "x").DepTolerant(opts, "y")  // want `missing dependency "e" -> "y"`
+	group.MakeRunner(func() error { return nil }).Name(opts, "f").DepQuorum(opts, 1, "a", "z").Finally(opts) // want `missing dependency "f" -> "z"`
+}
+
+// missing deps are not reported for the Options with dynamic names
+func dynamic(name string) {
+	var opts = group.Opts(group.WithDep)
+	group.MakeRunner(func() error { return nil }).Name(opts, name)
+	group.MakeRunner(func() error { return nil }).Name(opts, "a").Dep(opts, "x")
+	group.MakeRunner(func() error { return nil }).Name(opts, "b").Dep(opts, "b") // want `self dependency "b"`
+}
@@ -0,0 +1,224 @@
+// Command grun runs the shell tasks in a task file with the group dependency engine
+//
+//	grun -f grun.json
+//
+// task file:
+//
+//	{
+//		"prefix": "build", "limit": 4, "timeout": "10m",
+//		"tasks": [
+//			{"name": "gen", "cmd": "go generate ./..."},
+//			{"name": "lint", "cmd": "go vet ./...", "deps": ["gen"], "tolerant": true},
+//			{"name": "test", "cmd": "go test ./...", "deps": ["gen"], "timeout": "5m"},
+//			{"name": "build", "cmd": "go build -o bin/ ./...", "deps": ["lint", "test"], "dir": ".", "env": ["CGO_ENABLED=0"]}
+//		]
+//	}
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/oatcatx/group"
+)
+
+type taskFile struct {
+	Prefix  string         `json:"prefix,omitempty"`
+	Limit   int            `json:"limit,omitempty"`
+	Timeout group.Duration `json:"timeout,omitempty"`
+	Tasks   []task         `json:"tasks"`
+}
+
+type task struct {
+	Name     string         `json:"name"`
+	Cmd      string         `json:"cmd"`
+	Deps     []string       `json:"deps,omitempty"`
+	Tolerant bool           `json:"tolerant,omitempty"`
+	Tolerate []string       `json:"tolerate,omitempty"` // deps whose failure is tolerated by the task
+	Optional []string       `json:"optional,omitempty"` // deps that may be absent
+	Finally  bool           `json:"finally,omitempty"`  // cleanup task, run even if the group failed or timed out
+	Timeout  group.Duration `json:"timeout,omitempty"`
+	Dir      string         `json:"dir,omitempty"`
+	Env      []string       `json:"env,omitempty"` // KEY=VALUE, appended to the current env
+}
+
+// task result
+type result struct {
+	start, end time.Time
+	code       int
+	err        error
+}
+
+// task results of a run, the tasks killed on the group timeout may still be writing
+type report struct {
+	mu      sync.Mutex // guards the results & the output
+	results map[string]*result
+}
+
+func main() {
+	file := flag.String("f", "grun.json", "task file")
+	limit := flag.Int("limit", 0, "concurrency limit, overrides the task file")
+	timeout := flag.Duration("timeout", 0, "group timeout, overrides the task file")
+	log := flag.Bool("log", false, "enable group log")
+	flag.Parse()
+
+	tf, err := load(*file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "grun:", err)
+		os.Exit(2)
+	}
+	if *limit > 0 {
+		tf.Limit = *limit
+	}
+	if *timeout > 0 {
+		tf.Timeout = group.Duration(*timeout)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	rep, err := run(ctx, tf, *log, os.Stdout)
+	summary(os.Stdout, tf, rep, err)
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+func load(name string) (*taskFile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	var tf taskFile
+	if err := dec.Decode(&tf); err != nil {
+		return nil, fmt.Errorf("invalid task file %s: %w", name, err)
+	}
+	return &tf, nil
+}
+
+// runs the tasks as a group spec, output of each task is prefixed with its name
+func run(ctx context.Context, tf *taskFile, log bool, out io.Writer) (*report, error) {
+	rep := &report{results: make(map[string]*result, len(tf.Tasks))}
+	mu, results := &rep.mu, rep.results
+	spec := &group.Spec{Prefix: tf.Prefix, Limit: tf.Limit, Timeout: tf.Timeout, WithLog: log}
+	reg := group.NewRegistry()
+	for _, t := range tf.Tasks {
+		spec.Nodes = append(spec.Nodes, group.NodeSpec{Name: t.Name, Deps: t.Deps, Tolerant: t.Tolerant, Tolerate: t.Tolerate, Optional: t.Optional, Finally: t.Finally, Timeout: t.Timeout})
+		reg.RegisterContext(t.Name, func(ctx context.Context) error {
+			r := &result{start: time.Now()}
+			mu.Lock()
+			results[t.Name] = r
+			mu.Unlock()
+
+			w := &prefixWriter{mu: mu, w: out, prefix: fmt.Sprintf("[%s] ", t.Name)}
+			defer w.Flush()
+			cmd := exec.CommandContext(ctx, "sh", "-c", t.Cmd)
+			cmd.Dir, cmd.Stdout, cmd.Stderr = t.Dir, w, w
+			cmd.Env = append(os.Environ(), t.Env...)
+			cmd.WaitDelay = time.Second
+			err := exitErr(cmd.Run())
+			if err != nil && ctx.Err() != nil {
+				err = fmt.Errorf("killed: %w", ctx.Err())
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			r.end, r.err = time.Now(), err
+			if x := (*exitError)(nil); errors.As(err, &x) {
+				r.code = x.code
+			}
+			return err
+		})
+	}
+	return rep, spec.Go(ctx, reg)
+}
+
+// exitError maps the exit code of a task
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func exitErr(err error) error {
+	if x := (*exec.ExitError)(nil); errors.As(err, &x) {
+		return &exitError{code: x.ExitCode()}
+	}
+	return err
+}
+
+func summary(w io.Writer, tf *taskFile, rep *report, err error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	fmt.Fprintln(w, "\n== grun summary")
+	for _, t := range tf.Tasks {
+		r := rep.results[t.Name]
+		switch {
+		case r == nil:
+			fmt.Fprintf(w, "%-8s %s\n", "SKIP", t.Name)
+		case r.end.IsZero():
+			fmt.Fprintf(w, "%-8s %s\n", "ABORT", t.Name)
+		case r.err != nil:
+			fmt.Fprintf(w, "%-8s %s (%s) %v%s\n", "FAIL", t.Name, r.end.Sub(r.start).Round(time.Millisecond), r.err, cond(t.Tolerant, " [tolerated]", ""))
+		default:
+			fmt.Fprintf(w, "%-8s %s (%s)\n", "OK", t.Name, r.end.Sub(r.start).Round(time.Millisecond))
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(w, "FAILED: %v\n", err)
+		return
+	}
+	fmt.Fprintln(w, "PASSED")
+}
+
+// prefixWriter prefixes each line written to w
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte // partial line
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range b {
+		p.buf = append(p.buf, c)
+		if c == '\n' {
+			if _, err := fmt.Fprintf(p.w, "%s%s", p.prefix, p.buf); err != nil {
+				return 0, err
+			}
+			p.buf = p.buf[:0]
+		}
+	}
+	return len(b), nil
+}
+
+func (p *prefixWriter) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.buf) > 0 {
+		fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf)
+		p.buf = p.buf[:0]
+	}
+}
+
+func cond[T any](cond bool, t, f T) T {
+	if cond {
+		return t
+	}
+	return f
+}
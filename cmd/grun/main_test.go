@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func taskFileOf(t *testing.T, src string) *taskFile {
+	name := filepath.Join(t.TempDir(), "grun.json")
+	assert.Nil(t, os.WriteFile(name, []byte(src), 0o644))
+	tf, err := load(name)
+	assert.Nil(t, err)
+	return tf
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	tf := taskFileOf(t, `{
+		"prefix": "test",
+		"tasks": [
+			{"name": "ok", "cmd": "echo hello; printf partial"},
+			{"name": "fail", "cmd": "exit 3", "deps": ["ok"]},
+			{"name": "skip", "cmd": "echo never", "deps": ["fail"]}
+		]
+	}`)
+	var out bytes.Buffer
+	rep, err := run(context.Background(), tf, false, &out)
+	assert.EqualError(t, err, "exit status 3")
+	summary(&out, tf, rep, err)
+	assert.Equal(t, 3, rep.results["fail"].code)
+	s := out.String()
+	assert.Contains(t, s, "[ok] hello\n[ok] partial\n")
+	assert.NotContains(t, s, "never")
+	assert.Regexp(t, `OK +ok \(`, s)
+	assert.Regexp(t, `FAIL +fail \(.*\) exit status 3\n`, s)
+	assert.Regexp(t, `SKIP +skip\n`, s)
+	assert.Contains(t, s, "FAILED: exit status 3\n")
+
+	// node timeout
+	tf = taskFileOf(t, `{"tasks": [{"name": "slow", "cmd": "exec sleep 5", "timeout": "100ms"}]}`)
+	out.Reset()
+	rep, err = run(context.Background(), tf, false, &out)
+	assert.EqualError(t, err, "killed: context deadline exceeded")
+	summary(&out, tf, rep, err)
+	assert.Regexp(t, `FAIL +slow \(.*\) killed: context deadline exceeded\n`, out.String())
+
+	tf = taskFileOf(t, `{"tasks": [{"name": "a", "cmd": "true"}, {"name": "b", "cmd": "true", "deps": ["a"]}]}`)
+	out.Reset()
+	rep, err = run(context.Background(), tf, false, &out)
+	assert.Nil(t, err)
+	summary(&out, tf, rep, err)
+	assert.Regexp(t, `OK +a \(.*\)\nOK +b \(.*\)\nPASSED\n$`, out.String())
+}
+
+// the tasks killed on the group timeout settle while the summary is written
+func TestRunTimeout(t *testing.T) {
+	t.Parallel()
+
+	tf := taskFileOf(t, `{
+		"timeout": "100ms",
+		"tasks": [
+			{"name": "slow", "cmd": "echo start; exec sleep 5"},
+			{"name": "next", "cmd": "true", "deps": ["slow"]},
+			{"name": "cleanup", "cmd": "echo cleanup", "deps": ["slow"], "finally": true}
+		]
+	}`)
+	var out syncBuffer
+	rep, err := run(context.Background(), tf, false, &out)
+	assert.EqualError(t, err, "group timeout")
+	summary(&out, tf, rep, err)
+	s := out.String()
+	assert.Regexp(t, `(ABORT|FAIL) +slow`, s)
+	assert.Regexp(t, `SKIP +next\n`, s)
+	assert.Regexp(t, `OK +cleanup \(`, s)
+	assert.Contains(t, s, "FAILED: group timeout\n")
+}
+
+func TestPrefixWriter(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var out bytes.Buffer
+	w := &prefixWriter{mu: &mu, w: &out, prefix: "[a] "}
+	n, err := w.Write([]byte("one\ntw"))
+	assert.Nil(t, err)
+	assert.Equal(t, 6, n)
+	assert.Equal(t, "[a] one\n", out.String()) // partial line is buffered
+	_, _ = w.Write([]byte("o\n\nthree"))
+	w.Flush()
+	w.Flush()
+	assert.Equal(t, "[a] one\n[a] two\n[a] \n[a] three\n", out.String())
+	assert.Equal(t, 4, strings.Count(out.String(), "[a] "))
+}
+
+// buffer read while the killed tasks may still write
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,108 @@
+package group
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RunState is the snapshot of a group run in flight, see WithDebug and Inflight
+type RunState struct {
+	ID      uint64
+	Prefix  string
+	Start   time.Time
+	Limit   int
+	Timeout time.Duration
+	Runners []RunnerState // sorted by name
+}
+
+// RunnerState is the state of a runner in a run, funcs of the same name share one state
+type RunnerState struct {
+	Name    string
+	State   EventKind // Queued for waiting on deps or a slot
+	Since   time.Time // when the state is entered
+	Deps    []string
+	Waiting []string // unsettled deps of the queued runner
+	Err     error
+}
+
+var inflight = struct {
+	sync.Mutex
+	id   atomic.Uint64
+	runs map[uint64]*debugRun
+}{runs: make(map[uint64]*debugRun)}
+
+// Inflight returns the runs in flight of the groups with WithDebug, sorted by start time
+func Inflight() []RunState {
+	inflight.Lock()
+	runs := slices.Collect(maps.Values(inflight.runs))
+	inflight.Unlock()
+	states := make([]RunState, 0, len(runs))
+	for _, r := range runs {
+		states = append(states, r.state())
+	}
+	slices.SortFunc(states, func(a, b RunState) int { return cmp.Or(a.Start.Compare(b.Start), cmp.Compare(a.ID, b.ID)) })
+	return states
+}
+
+// debug observer of a run, registered in flight until closed
+type debugRun struct {
+	mu      sync.Mutex
+	run     RunState
+	deps    map[string][]string
+	runners map[string]*RunnerState
+}
+
+func newDebugRun(o *Options) *debugRun {
+	r := &debugRun{
+		run:     RunState{ID: inflight.id.Add(1), Prefix: o.Prefix, Start: time.Now(), Limit: o.Limit, Timeout: o.Timeout},
+		deps:    make(map[string][]string, len(o.dep)),
+		runners: make(map[string]*RunnerState),
+	}
+	for _, fd := range o.dep.present() {
+		r.deps[runnerName(fd.deps[0], fd.f)] = slices.Clone(fd.deps[1:])
+	}
+	inflight.Lock()
+	inflight.runs[r.run.ID] = r
+	inflight.Unlock()
+	return r
+}
+
+func (r *debugRun) event(kind EventKind, name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.runners[name]
+	if s == nil {
+		s = &RunnerState{Name: name, Deps: r.deps[name]}
+		r.runners[name] = s
+	}
+	s.State, s.Since, s.Err = kind, time.Now(), err
+}
+
+func (r *debugRun) close() {
+	inflight.Lock()
+	delete(inflight.runs, r.run.ID)
+	inflight.Unlock()
+}
+
+func (r *debugRun) state() RunState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.run
+	s.Runners = make([]RunnerState, 0, len(r.runners))
+	for _, x := range r.runners {
+		x := *x
+		if x.State == Queued {
+			x.Waiting = filter(x.Deps, func(dep string) bool {
+				d := r.runners[dep]
+				return d == nil || d.State == Queued || d.State == Started
+			})
+		}
+		s.Runners = append(s.Runners, x)
+	}
+	slices.SortFunc(s.Runners, func(a, b RunnerState) int { return cmp.Compare(a.Name, b.Name) })
+	return s
+}
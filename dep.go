@@ -1,7 +1,13 @@
 package group
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 )
 
 // fptr -> dependency struct
@@ -9,11 +15,23 @@ type depMap map[uintptr]*fdep
 
 // dependency struct -> fn, deps
 type fdep = struct {
-	f    func() error
-	deps []string // dependency list, first element is the func itself (name)
+	f        func() error
+	fc       func(context.Context) error // context-aware func, replaces f if set
+	deps     []string                    // dependency list, first element is the func itself (name)
+	tolerant bool                        // marked by Tolerant
+	tol      []string                    // deps whose failure is tolerated, set by DepTolerant
+	final    bool                        // marked by Finally
+	comp     func(context.Context) error // compensation, set by Compensate
+	soft     []string                    // optional deps, set by SoftDep
+	joins    []join                      // k-of-n joins of the deps, set by DepQuorum
+}
+
+// k-of-n join of the deps, the runner waits for k of the deps to succeed instead of all
+type join struct {
+	k    int
+	deps []string
 }
 
-type signal = chan token
 type token = struct{}
 
 type runner func() error
@@ -22,6 +40,17 @@ func MakeRunner(f func() error) runner {
 	return f
 }
 
+// MakeContextRunner makes a runner of the context-aware f
+// f gets the group ctx carrying the run Store, see Put and Get
+func MakeContextRunner(opts *Options, f func(context.Context) error) runner {
+	r := func() error { return f(context.Background()) }
+	if opts.ctxf == nil {
+		opts.ctxf = make(map[uintptr]func(context.Context) error, 1)
+	}
+	opts.ctxf[fptr(r)] = f
+	return r
+}
+
 func (r runner) Name(opts *Options, name string) runner {
 	if opts.dep == nil {
 		panic("dep not enabled")
@@ -29,8 +58,12 @@ func (r runner) Name(opts *Options, name string) runner {
 	if opts.dep[fptr(r)] == nil {
 		opts.dep[fptr(r)] = &fdep{
 			f:    r,
+			fc:   opts.ctxf[fptr(r)],
 			deps: []string{name}, // empty name is treated as anonymous
 		}
+	} else if opts.dep[fptr(r)].deps[0] == "" {
+		// registered anonymous by the calls before, e.g. Finally
+		opts.dep[fptr(r)].deps[0] = name
 	}
 	return r
 }
@@ -44,7 +77,8 @@ func (r runner) Dep(opts *Options, names ...string) runner {
 	}
 	if opts.dep[fptr(r)] == nil {
 		opts.dep[fptr(r)] = &fdep{
-			f: r,
+			f:  r,
+			fc: opts.ctxf[fptr(r)],
 			// auto anonymous for non-named runners
 			// anounymous runners can't be dependent
 			deps: append(append(make([]string, 0, len(names)+1), ""), filter(names, func(name string) bool { return name != "" })...),
@@ -62,96 +96,233 @@ func (r runner) Tolerant(opts *Options) runner {
 	if opts.dep == nil {
 		panic("dep not enabled")
 	}
-	// anonymous runner can't be fatal, ignore
-	if len(opts.dep[fptr(r)].deps) == 0 || opts.dep[fptr(r)].deps[0] == "" {
-		return r
+	if opts.dep[fptr(r)] == nil {
+		opts.dep[fptr(r)] = &fdep{f: r, fc: opts.ctxf[fptr(r)], deps: []string{""}}
+	}
+	// anonymous runner can't be fatal, ignored (reported by validation)
+	opts.dep[fptr(r)].tolerant = true
+	return r
+}
+
+// Finally marks the runner as a finalizer, it runs once its deps are settled even if the group failed,
+// or right away on the group timeout, on a ctx not cancelled by the group, with its own Timeout deadline
+// its err is joined to the group err, Go waits for the finalizers on timeout as well
+func (r runner) Finally(opts *Options) runner {
+	if opts.dep == nil {
+		panic("dep not enabled")
 	}
-	if opts.tol == nil {
-		opts.tol = make(map[string]token, 1)
+	if opts.dep[fptr(r)] == nil {
+		opts.dep[fptr(r)] = &fdep{f: r, fc: opts.ctxf[fptr(r)], deps: []string{""}}
 	}
-	opts.tol[opts.dep[fptr(r)].deps[0]] = token{}
+	opts.dep[fptr(r)].final = true
 	return r
 }
 
-func (r runner) Verify(opts *Options) runner {
-	opts.dep.verify(true)
+// Compensate attaches the compensation c of the runner, if the group fails, the compensations of the succeeded runners
+// are run one at a time in the reverse topological order, each on a ctx not cancelled by the group with its own Timeout deadline
+// the group err is returned as *CompensationError, on timeout Go waits for the runners with compensation still running
+func (r runner) Compensate(opts *Options, c func(context.Context) error) runner {
+	if opts.dep == nil {
+		panic("dep not enabled")
+	}
+	if opts.dep[fptr(r)] == nil {
+		opts.dep[fptr(r)] = &fdep{f: r, fc: opts.ctxf[fptr(r)], deps: []string{""}}
+	}
+	opts.dep[fptr(r)].comp = c
 	return r
 }
 
-func notify(s signal) {
-	if s != nil {
-		close(s)
+// DepTolerant adds deps whose failure is tolerated by the runner, the failure of the other deps is still fatal
+// e.g. the runner can run on if "cache" fails but not if "db" fails
+func (r runner) DepTolerant(opts *Options, names ...string) runner {
+	r.Dep(opts, names...)
+	if fd := opts.dep[fptr(r)]; fd != nil {
+		fd.tol = append(fd.tol, filter(names, func(name string) bool { return name != "" })...)
 	}
+	return r
 }
 
-func (d depMap) verify(panicking bool) string {
-	if len(d) == 0 {
-		return ""
+// DepAny adds a join of the deps, the runner starts once any of them succeeded, e.g. the fastest replica
+func (r runner) DepAny(opts *Options, names ...string) runner {
+	return r.DepQuorum(opts, 1, names...)
+}
+
+// DepQuorum adds a join of the deps, the runner starts once k of them succeeded instead of all
+// the failure of a dep only joined by its dependents is not fatal unless a quorum can't be reached,
+// the deps no longer needed can be cancelled by WithJoinCancel, k out of 1..len(names) is reported by ValidateDep
+func (r runner) DepQuorum(opts *Options, k int, names ...string) runner {
+	names = filter(names, func(name string) bool { return name != "" })
+	r.Dep(opts, names...)
+	if fd := opts.dep[fptr(r)]; fd != nil && len(names) > 0 {
+		fd.joins = append(fd.joins, join{k: k, deps: names})
 	}
-	graph, src := make(map[string][]string, len(d)), make(map[string]token, len(d))
+	return r
+}
+
+// reports whether dep is joined by the runner of fd
+func joined(fd *fdep, dep string) bool {
+	return slices.ContainsFunc(fd.joins, func(j join) bool { return slices.Contains(j.deps, dep) })
+}
+
+// reports whether k is within 1..len(deps), an invalid join fails once decided
+func (j join) valid() bool {
+	return j.k >= 1 && j.k <= len(j.deps)
+}
+
+// returns the err of the join if no quorum is reached with ok deps succeeded, errs are the errors of the failed deps
+func (j join) err(ok int, errs []error) error {
+	if !j.valid() {
+		return fmt.Errorf("invalid quorum %d of %s", j.k, strings.Join(j.deps, ", "))
+	}
+	if ok >= j.k {
+		return nil
+	}
+	return fmt.Errorf("quorum %d of %s not reached: %w", j.k, strings.Join(j.deps, ", "), errors.Join(errs...))
+}
+
+// returns the names of the runners only joined by their dependents, their failure is not fatal by itself, nil if no join is set
+func (d depMap) joinOnly() map[string]token {
+	var only map[string]token
 	for _, fd := range d {
-		if len(fd.deps) == 0 {
-			continue
-		}
-		// skip anonymous runner
-		if fd.deps[0] == "" {
-			continue
+		if len(fd.joins) > 0 {
+			only = make(map[string]token)
+			break
 		}
-		node := fd.deps[0]
-		if _, ok := src[node]; ok {
-			return fmt.Sprintf("duplicate dependency source %q", node)
-		}
-		graph[node], src[node] = make([]string, 0, len(fd.deps)-1), token{}
-		for i := 1; i < len(fd.deps); i++ {
-			if fd.deps[i] != "" {
-				graph[node] = append(graph[node], fd.deps[i])
+	}
+	if only == nil {
+		return nil
+	}
+	plain := make(map[string]token)
+	for _, fd := range d {
+		for _, dep := range fd.deps[1:] {
+			if joined(fd, dep) {
+				only[dep] = token{}
+			} else {
+				plain[dep] = token{}
 			}
 		}
 	}
+	for dep := range plain {
+		delete(only, dep)
+	}
+	return only
+}
 
-	// check existence
-	for _, deps := range graph {
-		for _, dep := range deps {
-			if _, ok := src[dep]; !ok {
-				var missing = fmt.Sprintf("missing dependency %q -> %q", dep, dep)
-				if panicking {
-					panic(missing)
-				}
-				return missing
+// SoftDep adds optional deps, the runner waits for them if present, or proceeds if they are not registered,
+// e.g. feature-flagged runners, validation treats the edges as optional
+func (r runner) SoftDep(opts *Options, names ...string) runner {
+	r.Dep(opts, names...)
+	if fd := opts.dep[fptr(r)]; fd != nil {
+		fd.soft = append(fd.soft, filter(names, func(name string) bool { return name != "" })...)
+	}
+	return r
+}
+
+// returns the view of depMap without the absent soft deps, depMap is returned as is if none
+func (d depMap) present() depMap {
+	var named map[string]token
+	absent := func(fd *fdep, dep string) bool {
+		if named == nil {
+			named = make(map[string]token, len(d))
+			for _, fd := range d {
+				named[fd.deps[0]] = token{}
 			}
 		}
+		_, ok := named[dep]
+		return !ok && slices.Contains(fd.soft, dep)
 	}
-
-	// check cycle dfs
-	stk, visited := make(map[string]token), make(map[string]token)
-	var dfs func(node string) string
-	dfs = func(node string) string {
-		if _, ok := stk[node]; ok {
-			return fmt.Sprintf("%q", node)
+	var r depMap
+	for p, fd := range d {
+		if len(fd.soft) == 0 || !slices.ContainsFunc(fd.deps[1:], func(dep string) bool { return absent(fd, dep) }) {
+			continue
 		}
-		if _, ok := visited[node]; ok {
-			return ""
+		if r == nil {
+			r = maps.Clone(d)
+		}
+		x := *fd
+		x.deps = append([]string{fd.deps[0]}, filter(fd.deps[1:], func(dep string) bool { return !absent(fd, dep) })...)
+		r[p] = &x
+	}
+	if r == nil {
+		return d
+	}
+	return r
+}
+
+// Verify panics if the dependencies set prior to the call are broken
+func (r runner) Verify(opts *Options) runner {
+	if err := opts.dep.verify().fatal(); err != nil {
+		panic(err.Error())
+	}
+	return r
+}
+
+// returns the func to run, context-aware func is bound to ctx
+// the store scope is of the deps declared in d, fd may be a run-time copy without the reduced or absent deps
+func bind(d depMap, fd *fdep, ctx context.Context) func() error {
+	if fd.fc == nil {
+		return fd.f
+	}
+	decl := cmp.Or(d[fptr(fd.f)], fd)
+	return func() error { return fd.fc(withScope(ctx, decl)) }
+}
+
+// returns the func to run, context-aware func of the runner without deps is bound to ctx
+func (o *Options) bind(f func() error, ctx context.Context) func() error {
+	if o == nil || o.ctxf[fptr(f)] == nil {
+		return f
+	}
+	fc := o.ctxf[fptr(f)]
+	return func() error { return fc(ctx) }
+}
+
+// dependency graph view of depMap
+type depGraph struct {
+	nodes []string            // sorted runner labels
+	deps  map[string][]string // runner label -> deps
+	anon  map[string]token    // labels of anonymous runners
+	dup   []string            // duplicate names
+}
+
+// builds the dependency graph, anonymous runners are labelled by func name
+func (d depMap) graph() *depGraph {
+	d = d.present()
+	g := &depGraph{deps: make(map[string][]string, len(d)), anon: make(map[string]token)}
+	// stable order for anonymous labels
+	ptrs := slices.SortedFunc(maps.Keys(d), func(a, b uintptr) int {
+		return cmp.Or(cmp.Compare(d[a].deps[0], d[b].deps[0]), cmp.Compare(funcName(d[a].f), funcName(d[b].f)), cmp.Compare(a, b))
+	})
+	for _, p := range ptrs {
+		fd := d[p]
+		if len(fd.deps) == 0 {
+			continue
 		}
-		stk[node], visited[node] = token{}, token{}
-		for _, dep := range graph[node] {
-			if x := dfs(dep); x != "" {
-				return fmt.Sprintf("%q -> %s", node, x)
+		node := fd.deps[0]
+		if node == "" {
+			node = funcName(fd.f)
+			for i := 2; g.deps[node] != nil; i++ {
+				node = fmt.Sprintf("%s#%d", funcName(fd.f), i)
 			}
+			g.anon[node] = token{}
+		} else if _, ok := g.deps[node]; ok {
+			g.dup = append(g.dup, node)
+			continue
 		}
-		delete(stk, node)
-		return ""
-	}
-	// check cycle
-	for node := range graph {
-		if _, ok := visited[node]; !ok {
-			if x := dfs(node); x != "" {
-				var cycle = fmt.Sprintf("dependency cycle detected: %s", x)
-				if panicking {
-					panic(cycle)
-				}
-				return cycle
+		g.deps[node] = make([]string, 0, len(fd.deps)-1)
+		for _, dep := range fd.deps[1:] {
+			if dep != "" && !slices.Contains(g.deps[node], dep) {
+				g.deps[node] = append(g.deps[node], dep)
 			}
 		}
+		g.nodes = append(g.nodes, node)
 	}
-	return ""
+	slices.Sort(g.nodes)
+	return g
+}
+
+// reports whether name is a dependable node
+func (g *depGraph) has(name string) bool {
+	_, ok := g.deps[name]
+	_, anon := g.anon[name]
+	return ok && !anon
 }
@@ -1,9 +1,16 @@
 package group
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -174,6 +181,50 @@ func TestGroupGoDepNFF(t *testing.T) {
 	assert.Equal(t, 1, c.x)
 }
 
+func TestGroupGoDepNFFContext(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	// the tolerated runner is not cancelled by the dep err, and has the group deadline
+	var alive, deadline bool
+	reg := NewRegistry().
+		Register("f", func() error { return errors.New("f") }).
+		RegisterContext("x", func(ctx context.Context) error {
+			_, deadline = ctx.Deadline()
+			alive = ctx.Err() == nil
+			return nil
+		})
+	spec := &Spec{Timeout: Duration(time.Second), Nodes: []NodeSpec{{Name: "f", Tolerant: true}, {Name: "x", Deps: []string{"f"}}}}
+	assert.EqualError(t, spec.Go(ctx, reg), "f")
+	assert.True(t, alive)
+	assert.True(t, deadline)
+}
+
+func TestGroupGoDepNFFIntercept(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	// the interceptors of the tolerated runner see its ctx, not the cancelled group ctx
+	var ran bool
+	latency := func(ctx context.Context, name string, f func() error) error {
+		if name == "b" {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		return f()
+	}
+	var opts = Opts(WithDep, WithInterceptor(latency))
+	err := Go(ctx, opts,
+		MakeRunner(func() error { return errors.New("a") }).Name(opts, "a").Tolerant(opts),
+		MakeRunner(func() error { ran = true; return nil }).Name(opts, "b").Dep(opts, "a"))
+
+	assert.EqualError(t, err, "a")
+	assert.True(t, ran)
+}
+
 //go:norace
 func TestGroupGoTimeout(t *testing.T) {
 	t.Parallel()
@@ -214,6 +265,23 @@ func TestGroupGoDepTimeout(t *testing.T) {
 	assert.Equal(t, 0, c.d)
 }
 
+func TestGroupGoTimeoutDone(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	s := time.Now()
+
+	// returns once the funcs are done, not at the timeout
+	var opts = Opts(WithTimeout(1 * time.Second))
+	assert.Nil(t, Go(ctx, opts, func() error { return nil }, func() error { return nil }))
+	ok, err := TryGo(ctx, opts, func() error { return nil })
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	err = Go(ctx, opts, func() error { return errors.New("x") })
+	assert.EqualError(t, err, "x")
+	assert.Less(t, time.Since(s), time.Second)
+}
+
 func TestGroupGoVerify(t *testing.T) {
 	t.Parallel()
 
@@ -337,3 +405,889 @@ func TestGroupTryGoNotOk(t *testing.T) {
 	assert.Equal(t, 4, c.Res())
 	assert.Equal(t, float64(4), time.Since(s).Truncate(time.Second).Seconds())
 }
+
+func TestGroupGoPanic(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	var pe *PanicError
+	err := Go(ctx, nil, func() error { panic("boom") }, func() error { return nil })
+	assert.ErrorAs(t, err, &pe)
+	assert.Equal(t, "boom", pe.Value)
+	assert.NotEmpty(t, pe.Stack)
+
+	// fails the group, the dependents are not run
+	var ran bool
+	var opts = Opts(WithDep)
+	err = Go(ctx, opts,
+		MakeRunner(func() error { panic("boom") }).Name(opts, "a"),
+		MakeRunner(func() error { ran = true; return nil }).Name(opts, "b").Dep(opts, "a"))
+	assert.ErrorAs(t, err, &pe)
+	assert.EqualError(t, err, "runtime panic: boom")
+	assert.False(t, ran)
+}
+
+func TestGroupPlan(t *testing.T) {
+	t.Parallel()
+
+	c := new(exampleCtx)
+	var opts = Opts(WithDep)
+	MakeRunner(c.A).Name(opts, "a")
+	MakeRunner(c.B).Name(opts, "b").Dep(opts, "a")
+	MakeRunner(c.C).Name(opts, "c").Dep(opts, "a")
+	MakeRunner(c.D).Name(opts, "d").Dep(opts, "b", "c")
+	MakeRunner(c.E).Name(opts, "e").Dep(opts, "c")
+	MakeRunner(c.F).Name(opts, "f")
+
+	p, err := opts.Plan()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"a", "f"}, {"b", "c"}, {"d", "e"}}, p.Waves)
+	assert.Equal(t, []string{"a", "f", "b", "c", "d", "e"}, p.Order)
+	assert.Equal(t, PlanNode{Depth: 1, FanIn: 1, FanOut: 2}, p.Nodes["c"])
+	assert.Equal(t, 3, p.MaxConcurrency) // f, d, e
+
+	MakeRunner(c.X).Name(opts, "x").Dep(opts, "y")
+	_, err = opts.Plan()
+	assert.NotNil(t, err)
+}
+
+func TestGroupGraph(t *testing.T) {
+	t.Parallel()
+
+	c := new(exampleCtx)
+	var opts = Opts(WithDep)
+	MakeRunner(c.A).Name(opts, "a")
+	MakeRunner(c.B).Name(opts, "b").Dep(opts, "a")
+	MakeRunner(c.C).Name(opts, "c").Dep(opts, "a").Tolerant(opts)
+	MakeRunner(c.D).Name(opts, "d").Dep(opts, "b", "c")
+	MakeRunner(c.E).Name(opts, "e").Dep(opts, "c")
+
+	g := opts.Graph()
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, g.Nodes())
+	assert.Equal(t, []string{"b", "c"}, g.Deps("d"))
+	assert.Equal(t, []string{"a", "b", "c"}, g.Ancestors("d"))
+	assert.Equal(t, []string{"d", "e"}, g.Descendants("c"))
+	assert.Equal(t, []string{"a"}, g.Roots())
+	assert.Equal(t, []string{"d", "e"}, g.Sinks())
+	assert.True(t, g.Tolerant("c"))
+	assert.False(t, g.Tolerant("b"))
+	order, err := g.TopoSort()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, order)
+}
+
+func TestGroupValidateDep(t *testing.T) {
+	t.Parallel()
+
+	var opts = Opts(WithDep)
+	var fs = make([]func() error, 9)
+	for i := range fs {
+		fs[i] = func() error { return fmt.Errorf("%d", i) } // distinct closures
+	}
+	MakeRunner(fs[0]).Name(opts, "a")
+	MakeRunner(fs[1]).Name(opts, "a")                // duplicate
+	MakeRunner(fs[2]).Name(opts, "b").Dep(opts, "x") // missing
+	MakeRunner(fs[3]).Name(opts, "c").Dep(opts, "a", "d")
+	MakeRunner(fs[4]).Name(opts, "d").Dep(opts, "c") // cycle
+	MakeRunner(fs[5]).Name(opts, "e").Dep(opts, "e") // self
+	MakeRunner(fs[6]).Name(opts, "f").Dep(opts, "b") // unreachable
+	MakeRunner(fs[7]).Dep(opts, "a").Tolerant(opts)  // anonymous tolerant
+	MakeRunner(fs[8]).Name(opts, "g")                // unused
+
+	var verr *ValidationError
+	assert.ErrorAs(t, opts.ValidateDep(), &verr)
+	var kinds []ProblemKind
+	for _, p := range verr.Problems {
+		kinds = append(kinds, p.Kind)
+	}
+	assert.Equal(t, []ProblemKind{DuplicateName, MissingDep, Cycle, SelfDep, Unreachable}, kinds)
+	assert.Equal(t, `missing dependency "b" -> "x"`, verr.Problems[1].String())
+	assert.Equal(t, `dependency cycle detected: "c" -> "d" -> "c"`, verr.Problems[2].String())
+	assert.Equal(t, []string{"b"}, verr.Problems[4].Path)
+	lint := opts.Lint()
+	assert.Len(t, lint, 2)
+	assert.Equal(t, AnonymousTolerant, lint[0].Kind)
+	assert.Equal(t, Problem{Kind: Unused, Node: "g"}, lint[1])
+
+	// the non-fatal problems only are not an err
+	opts = Opts(WithDep)
+	MakeRunner(fs[0]).Name(opts, "solo")
+	assert.Nil(t, opts.ValidateDep())
+	assert.Equal(t, []Problem{{Kind: Unused, Node: "solo"}}, opts.Lint())
+}
+
+func TestGroupGoDepReduce(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	c := new(exampleCtx)
+	s := time.Now()
+
+	var opts = Opts(WithDep, WithReduce)
+	err := Go(ctx, opts,
+		MakeRunner(c.A).Name(opts, "a"),
+		MakeRunner(c.B).Name(opts, "b").Dep(opts, "a"),
+		MakeRunner(c.C).Name(opts, "c").Dep(opts, "a"),
+		MakeRunner(c.D).Name(opts, "d").Dep(opts, "a", "b", "c")) // "a" is redundant
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Edge{{Node: "d", Dep: "a"}}, opts.Graph().Redundant())
+	assert.Equal(t, []string{"b", "c"}, opts.Graph().Reduce().Deps("d"))
+	assert.Equal(t, 4, c.Res())
+	assert.Equal(t, float64(4), time.Since(s).Truncate(time.Second).Seconds())
+}
+
+func TestGroupSpec(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	c := new(exampleCtx)
+	reg := NewRegistry().
+		Register("a", c.A).
+		Register("b", c.B).
+		Register("c", c.C).
+		Register("d", c.D).
+		RegisterContext("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+	spec, err := LoadSpec(strings.NewReader(`{
+		"prefix": "spec",
+		"nodes": [
+			{"name": "x", "func": "slow", "timeout": "100ms", "tolerant": true},
+			{"name": "a", "deps": ["x"]},
+			{"name": "b", "deps": ["a"]},
+			{"name": "c", "deps": ["a"]},
+			{"name": "d", "deps": ["b", "c"]}
+		]
+	}`))
+	assert.Nil(t, err)
+	s := time.Now()
+	err = spec.Go(ctx, reg)
+	assert.ErrorIs(t, err, context.DeadlineExceeded) // tolerated node timeout
+	assert.Equal(t, 4, c.Res())
+	assert.Equal(t, float64(4), time.Since(s).Truncate(time.Second).Seconds())
+
+	spec.Nodes = append(spec.Nodes, NodeSpec{Name: "e", Deps: []string{"y"}})
+	_, _, err = spec.Build(reg)
+	assert.ErrorContains(t, err, "not registered")
+	spec.Nodes[len(spec.Nodes)-1].Func = "a"
+	_, _, err = spec.Build(reg)
+	assert.ErrorContains(t, err, `missing dependency "e" -> "y"`)
+}
+
+func TestGroupGoStress(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	// b consumes the state written by a in each run
+	run := func(dep bool) error {
+		var state atomic.Int32
+		var opts = Opts(WithDep, WithStress(0, 20, 2*time.Millisecond))
+		b := MakeRunner(func() error {
+			if state.Swap(0) != 1 {
+				return errors.New("state not ready")
+			}
+			return nil
+		}).Name(opts, "b")
+		if dep {
+			b.Dep(opts, "a")
+		}
+		return Go(ctx, opts,
+			MakeRunner(func() error { state.Store(1); return nil }).Name(opts, "a"),
+			b)
+	}
+
+	assert.Nil(t, run(true))
+	var serr *StressError
+	assert.ErrorAs(t, run(false), &serr) // missing dep
+	assert.NotZero(t, serr.Seed)
+	assert.EqualError(t, serr.Err, "state not ready")
+}
+
+func TestGroupGoSerial(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	var order []string // no lock, runners are run on the caller goroutine
+	run := func(name string, err error) func() error {
+		return func() error {
+			order = append(order, name)
+			return err
+		}
+	}
+
+	var opts = Opts(WithDep, WithSerial)
+	err := Go(ctx, opts,
+		MakeRunner(run("d", nil)).Name(opts, "d").Dep(opts, "b", "c"),
+		MakeRunner(run("c", nil)).Name(opts, "c").Dep(opts, "a"),
+		MakeRunner(run("b", nil)).Name(opts, "b").Dep(opts, "a"),
+		MakeRunner(run("a", nil)).Name(opts, "a"),
+		run("x", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "c", "b", "d", "x"}, order)
+
+	// same fast-fail & tolerance semantics
+	order = nil
+	opts = Opts(WithDep, WithSerial)
+	err = Go(ctx, opts,
+		MakeRunner(run("f", errors.New("f"))).Name(opts, "f").Tolerant(opts),
+		MakeRunner(run("x", nil)).Name(opts, "x").Dep(opts, "f"),
+		run("y", nil)) // group failed before exec
+	assert.EqualError(t, err, "f")
+	assert.Equal(t, []string{"f", "x"}, order)
+}
+
+func TestGroupStore(t *testing.T) {
+	t.Parallel()
+
+	type user struct{ ID int }
+	var ctx = context.Background()
+	var opts = Opts(WithDep)
+	var total int
+	err := Go(ctx, opts,
+		MakeContextRunner(opts, func(ctx context.Context) error {
+			return Put(ctx, user{ID: 1})
+		}).Name(opts, "user"),
+		MakeContextRunner(opts, func(ctx context.Context) error {
+			return Put(ctx, []int{1, 2, 3})
+		}).Name(opts, "items"),
+		MakeContextRunner(opts, func(ctx context.Context) error {
+			u, err := Get[user](ctx, "user")
+			if err != nil {
+				return err
+			}
+			items, err := Get[[]int](ctx, "items")
+			if err != nil {
+				return err
+			}
+			for _, x := range items {
+				total += x * u.ID
+			}
+			return nil
+		}).Name(opts, "order").Dep(opts, "user", "items"))
+	assert.Nil(t, err)
+	assert.Equal(t, 6, total)
+
+	// undeclared dep & type mismatch
+	opts = Opts(WithDep)
+	err = Go(ctx, opts,
+		MakeContextRunner(opts, func(ctx context.Context) error { return Put(ctx, 1) }).Name(opts, "a"),
+		MakeContextRunner(opts, func(ctx context.Context) error { return Put(ctx, 2) }).Name(opts, "b").Dep(opts, "a"),
+		MakeContextRunner(opts, func(ctx context.Context) error {
+			_, err := Get[int](ctx, "a")
+			return err
+		}).Name(opts, "c").Dep(opts, "b"))
+	assert.EqualError(t, err, "undeclared dep a of c")
+
+	opts = Opts(WithDep, WithSerial)
+	err = Go(ctx, opts,
+		MakeContextRunner(opts, func(ctx context.Context) error { return Put(ctx, 1) }).Name(opts, "a"),
+		MakeContextRunner(opts, func(ctx context.Context) error {
+			_, err := Get[string](ctx, "a")
+			return err
+		}).Name(opts, "b").Dep(opts, "a"))
+	assert.EqualError(t, err, "value put by a is int, not string")
+
+	// the declared deps are readable though the wait is reduced
+	for _, serial := range []bool{false, true} {
+		opts = Opts(WithDep, WithReduce)
+		if serial {
+			opts = Opts(WithDep, WithReduce, WithSerial)
+		}
+		var got int
+		err = Go(ctx, opts,
+			MakeContextRunner(opts, func(ctx context.Context) error { return Put(ctx, 1) }).Name(opts, "a"),
+			MakeContextRunner(opts, func(ctx context.Context) error { return Put(ctx, 2) }).Name(opts, "b").Dep(opts, "a"),
+			MakeContextRunner(opts, func(ctx context.Context) error {
+				a, err := Get[int](ctx, "a")
+				got = a
+				return err
+			}).Name(opts, "c").Dep(opts, "a", "b"))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, got)
+	}
+
+	// runners without name can't access the store
+	opts = Opts(WithDep)
+	err = Go(ctx, opts, MakeContextRunner(opts, func(ctx context.Context) error { return Put(ctx, 1) }))
+	assert.EqualError(t, err, "store is only accessible by named runners")
+}
+
+func TestGroupProgress(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	var mu sync.Mutex
+	var ps []Progress
+	record := func(p Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		ps = append(ps, p)
+	}
+
+	var fs []func() error
+	for i := range 100 {
+		fs = append(fs, func() error { _ = fmt.Sprint(i); return nil })
+	}
+	assert.Nil(t, Go(ctx, Opts(WithProgress(record, 0)), fs...))
+	last := ps[len(ps)-1]
+	assert.True(t, last.Done)
+	assert.Equal(t, 100, last.Total)
+	assert.Equal(t, 100, last.Finished)
+	assert.Equal(t, 0, last.Queued+last.Running+last.Failed+last.Skipped)
+	assert.Equal(t, 300, len(ps)) // queued, started & finished events, throttle off
+
+	// throttled
+	ps = nil
+	assert.Nil(t, Go(ctx, Opts(WithProgress(record, time.Hour)), fs...))
+	assert.Equal(t, 2, len(ps)) // the first event & the last snapshot
+	assert.Equal(t, Queued, ps[0].Kind)
+	assert.True(t, ps[1].Done)
+
+	// failed & skipped
+	ps = nil
+	var opts = Opts(WithDep, WithSerial, WithProgress(record, 0))
+	err := Go(ctx, opts,
+		MakeRunner(func() error { return nil }).Name(opts, "a"),
+		MakeRunner(func() error { return errors.New("b") }).Name(opts, "b").Dep(opts, "a"),
+		MakeRunner(func() error { return nil }).Name(opts, "c").Dep(opts, "b"))
+	assert.EqualError(t, err, "b")
+	var events []string
+	for _, p := range ps {
+		events = append(events, p.Name+" "+p.Kind.String())
+	}
+	assert.Equal(t, []string{
+		"a queued", "b queued", "c queued",
+		"a started", "a finished", "b started", "b failed", "c skipped",
+	}, events)
+	last = ps[len(ps)-1]
+	assert.True(t, last.Done)
+	assert.Equal(t, []int{1, 1, 1}, []int{last.Finished, last.Failed, last.Skipped})
+
+	// channel
+	c := make(chan Progress, 1)
+	assert.Nil(t, Go(ctx, Opts(WithProgressC(c, time.Hour)), fs...))
+	assert.Equal(t, Queued, (<-c).Kind) // later ones are dropped
+}
+
+func TestGroupTrace(t *testing.T) {
+	var ctx = context.Background()
+	var buf bytes.Buffer
+	assert.Nil(t, trace.Start(&buf))
+	var profile string
+	var opts = Opts(WithDep, WithTrace, WithPrefix("traced"))
+	err := Go(ctx, opts,
+		MakeRunner(func() error { return nil }).Name(opts, "load"),
+		MakeRunner(func() error {
+			var b strings.Builder
+			_ = pprof.Lookup("goroutine").WriteTo(&b, 1)
+			profile = b.String()
+			return nil
+		}).Name(opts, "render").Dep(opts, "load"))
+	trace.Stop()
+	assert.Nil(t, err)
+	assert.Contains(t, profile, `"group":"traced"`)
+	assert.Contains(t, profile, `"runner":"render"`)
+	for _, s := range []string{"group traced", "load", "render", "group.wait"} {
+		assert.True(t, bytes.Contains(buf.Bytes(), []byte(s)), s)
+	}
+}
+
+func TestGroupGoAllocs(t *testing.T) {
+	var ctx = context.Background()
+	var fs []func() error
+	for i := range 10 {
+		fs = append(fs, func() error {
+			if i == 0 {
+				return errors.New("x")
+			}
+			return nil
+		})
+	}
+	errC := make(chan error, 1)
+	err := Go(ctx, Opts(WithErrorCollector(errC)), fs...)
+	assert.EqualError(t, err, "x")
+	assert.Contains(t, (<-errC).Error(), "TestGroupGoAllocs.func1 failed: x")
+
+	// no per-call closure & name resolving
+	fs = fs[1:]
+	allocs := testing.AllocsPerRun(100, func() { _ = Go(ctx, Opts(WithErrorCollector(errC)), fs...) })
+	assert.LessOrEqual(t, allocs, float64(len(fs)+10)) // one per func by errgroup
+}
+
+func TestGroupGoDepReady(t *testing.T) {
+	var ctx = context.Background()
+	base := runtime.NumGoroutine()
+	var peak atomic.Int64
+	var opts = Opts(WithDep)
+	var fs []func() error
+	for i := range 200 {
+		f := MakeRunner(func() error {
+			peak.Store(max(peak.Load(), int64(runtime.NumGoroutine())))
+			return nil
+		}).Name(opts, fmt.Sprint(i))
+		if i > 0 {
+			f.Dep(opts, fmt.Sprint(i-1))
+		}
+		fs = append(fs, f)
+	}
+	assert.Nil(t, Go(ctx, opts, fs...))
+	// no goroutine is parked on the deps of a chain
+	assert.Less(t, peak.Load(), int64(base+20))
+
+	// cycle is reported instead of hanging
+	opts = Opts(WithDep)
+	err := Go(ctx, opts,
+		MakeRunner(func() error { return nil }).Name(opts, "a"),
+		MakeRunner(func() error { return nil }).Name(opts, "b").Dep(opts, "a", "c"),
+		MakeRunner(func() error { return nil }).Name(opts, "c").Dep(opts, "b"))
+	assert.EqualError(t, err, "dependency cycle detected, no runner is ready")
+}
+
+func TestGroupGoPool(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	pool := NewPool(4, 16, 50*time.Millisecond)
+	defer pool.Close()
+
+	for range 100 {
+		var sum atomic.Int64
+		var opts = Opts(WithDep, WithPool(pool), WithLimit(8))
+		err := Go(ctx, opts,
+			MakeRunner(func() error { sum.Add(1); return nil }).Name(opts, "a"),
+			MakeRunner(func() error { sum.Add(2); return nil }).Name(opts, "b").Dep(opts, "a"),
+			MakeRunner(func() error { sum.Add(3); return nil }).Name(opts, "c").Dep(opts, "a"),
+			MakeRunner(func() error { sum.Add(4); return nil }).Name(opts, "d").Dep(opts, "b", "c"),
+			func() error { sum.Add(5); return nil })
+		assert.Nil(t, err)
+		assert.Equal(t, int64(15), sum.Load())
+		assert.LessOrEqual(t, pool.Workers(), 4)
+	}
+
+	// same fast-fail & limit semantics
+	var opts = Opts(WithPool(pool), WithLimit(1))
+	ok, err := TryGo(ctx, opts,
+		func() error { time.Sleep(10 * time.Millisecond); return errors.New("x") },
+		func() error { return nil })
+	assert.False(t, ok)
+	assert.EqualError(t, err, "x")
+	err = Go(ctx, Opts(WithPool(pool), WithTimeout(10*time.Millisecond)), func() error { time.Sleep(100 * time.Millisecond); return nil })
+	assert.EqualError(t, err, "group timeout")
+
+	// idle workers are reaped
+	assert.Eventually(t, func() bool { return pool.Workers() == 0 }, time.Second, 10*time.Millisecond)
+
+	// a subgroup sharing the pool doesn't queue behind its busy worker
+	small := NewPool(1, 4, 0)
+	defer small.Close()
+	var sum atomic.Int64
+	done := make(chan error, 1)
+	go func() {
+		opts := Opts(WithDep, WithPool(small))
+		sub := Opts(WithPool(small))
+		err := Go(ctx, opts,
+			MakeGroupRunner(opts, sub,
+				func() error { sum.Add(1); return nil },
+				func() error { sum.Add(2); return nil }).Name(opts, "sub"),
+			MakeRunner(func() error { sum.Add(3); return nil }).Name(opts, "a").Dep(opts, "sub"))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+		assert.Equal(t, int64(6), sum.Load())
+	case <-time.After(5 * time.Second):
+		t.Fatal("nested group on the pool deadlocked")
+	}
+}
+
+func TestGroupGoDepTolerant(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	for _, serial := range []bool{false, true} {
+		run := func(fail string) (bool, error) {
+			var ran atomic.Bool
+			var opts = Opts(WithDep)
+			if serial {
+				opts = Opts(WithDep, WithSerial)
+			}
+			err := Go(ctx, opts,
+				MakeRunner(func() error { return cond(fail == "db", errors.New("db"), nil) }).Name(opts, "db"),
+				// cache fails after db is settled
+				MakeRunner(func() error { return cond(fail == "cache", errors.New("cache"), nil) }).Name(opts, "cache").Dep(opts, "db"),
+				MakeRunner(func() error { ran.Store(true); return nil }).Name(opts, "order").Dep(opts, "db").DepTolerant(opts, "cache"))
+			return ran.Load(), err
+		}
+		// cache failure is tolerated by order
+		ran, err := run("cache")
+		assert.True(t, ran)
+		assert.EqualError(t, err, "cache")
+		// db failure is not
+		ran, err = run("db")
+		assert.False(t, ran)
+		assert.EqualError(t, err, "db")
+		ran, err = run("")
+		assert.True(t, ran)
+		assert.Nil(t, err)
+	}
+
+	// the waits through a tolerated edge are not reduced
+	for _, serial := range []bool{false, true} {
+		for _, reduce := range []bool{false, true} {
+			var ran atomic.Bool
+			with := []option{WithDep}
+			if reduce {
+				with = append(with, WithReduce)
+			}
+			if serial {
+				with = append(with, WithSerial)
+			}
+			var opts = Opts(with...)
+			err := Go(ctx, opts,
+				MakeRunner(func() error { return errors.New("a") }).Name(opts, "a"),
+				MakeRunner(func() error { return nil }).Name(opts, "b").DepTolerant(opts, "a"),
+				MakeRunner(func() error { ran.Store(true); return nil }).Name(opts, "c").Dep(opts, "a", "b"))
+			assert.EqualError(t, err, "a")
+			assert.False(t, ran.Load())
+		}
+	}
+	opts := Opts(WithDep, WithReduce)
+	MakeRunner(func() error { return nil }).Name(opts, "a")
+	MakeRunner(func() error { return nil }).Name(opts, "b").Dep(opts, "a").Tolerant(opts)
+	MakeRunner(func() error { return nil }).Name(opts, "c").Dep(opts, "a", "b")
+	MakeRunner(func() error { return nil }).Name(opts, "d").Dep(opts, "a", "c")
+	assert.Equal(t, []string{"a", "b"}, opts.deps().graph().deps["c"])
+	assert.Equal(t, []string{"c"}, opts.deps().graph().deps["d"])
+}
+
+func TestGroupGoErrorSink(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	var started sync.WaitGroup
+	fail := func(i int) func() error {
+		return func() error {
+			// all started before the first failure
+			started.Done()
+			started.Wait()
+			time.Sleep(time.Duration(i) * 10 * time.Millisecond)
+			return fmt.Errorf("x%d", i)
+		}
+	}
+	fs := []func() error{fail(0), fail(1), fail(2)}
+	started.Add(3)
+
+	// bounded, never blocks the runners
+	drop := NewDropSink(1)
+	assert.EqualError(t, Go(ctx, Opts(WithErrorSink(drop)), fs...), "x0")
+	assert.Contains(t, (<-drop.C()).Error(), "failed: x0")
+	assert.Equal(t, int64(2), drop.Dropped())
+	started.Add(1)
+
+	// not received within the timeout
+	errC := make(chan error)
+	wait := NewTimeoutSink(errC, 10*time.Millisecond)
+	assert.EqualError(t, Go(ctx, Opts(WithErrorSink(wait)), fs[0]), "x0")
+	assert.Equal(t, int64(1), wait.Dropped())
+	started.Add(3)
+
+	var n atomic.Int32
+	assert.EqualError(t, Go(ctx, Opts(WithErrorSink(SinkFunc(func(error) { n.Add(1) }))), fs...), "x0")
+	assert.Equal(t, int32(3), n.Load())
+	started.Add(3)
+
+	// in the order of failure
+	var c Collector
+	assert.EqualError(t, Go(ctx, Opts(WithErrorSink(&c)), fs[2], fs[0], fs[1]), "x0")
+	errs := c.Errors()
+	assert.Len(t, errs, 3)
+	for i, err := range errs {
+		assert.Contains(t, err.Error(), fmt.Sprintf("failed: x%d", i))
+	}
+	assert.ErrorContains(t, c.Err(), "failed: x2")
+}
+
+func TestGroupGoSubgroup(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	var sub = Opts(WithDep, WithLimit(2))
+	var ran atomic.Int32
+	fs := []func() error{
+		MakeRunner(func() error { ran.Add(1); return nil }).Name(sub, "x"),
+		MakeRunner(func() error { return errors.New("y") }).Name(sub, "y").Dep(sub, "x"),
+	}
+	var opts = Opts(WithDep, WithPrefix("agg"))
+	var after bool
+	err := Go(ctx, opts,
+		MakeRunner(func() error { ran.Add(1); return nil }).Name(opts, "a"),
+		MakeGroupRunner(opts, sub, fs...).Name(opts, "sub").Dep(opts, "a"),
+		MakeRunner(func() error { after = true; return nil }).Name(opts, "b").Dep(opts, "sub"))
+	var se *SubgroupError
+	assert.ErrorAs(t, err, &se)
+	assert.Equal(t, "agg/sub", se.Prefix)
+	assert.EqualError(t, se.Err, "y")
+	assert.Len(t, se.Errs, 1)
+	assert.Contains(t, se.Errs[0].Error(), "y failed: y")
+	assert.Equal(t, int32(2), ran.Load())
+	assert.False(t, after)
+
+	// cancelled with the parent
+	opts, sub = Opts(WithTimeout(10*time.Millisecond)), Opts()
+	cancelled := make(chan error, 1)
+	err = Go(ctx, opts, MakeGroupRunner(opts, sub, MakeContextRunner(sub, func(ctx context.Context) error {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return ctx.Err()
+	})))
+	assert.EqualError(t, err, "group timeout")
+	assert.ErrorIs(t, <-cancelled, context.DeadlineExceeded)
+}
+
+func TestGroupGoFinally(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	for _, serial := range []bool{false, true} {
+		var opts = Opts(WithDep)
+		if serial {
+			opts = Opts(WithDep, WithSerial)
+		}
+		var cleaned atomic.Bool
+		errFlush := errors.New("flush")
+		err := Go(ctx, opts,
+			MakeRunner(func() error { return errors.New("a") }).Name(opts, "a"),
+			MakeRunner(func() error { return nil }).Name(opts, "b").Dep(opts, "a"),
+			MakeContextRunner(opts, func(ctx context.Context) error {
+				cleaned.Store(ctx.Err() == nil) // not cancelled by the group failure
+				return errFlush
+			}).Name(opts, "cleanup").Dep(opts, "a", "b").Finally(opts))
+		assert.True(t, cleaned.Load())
+		// the original failure is kept
+		assert.ErrorIs(t, err, errFlush)
+		assert.True(t, strings.HasPrefix(err.Error(), "a\n"))
+	}
+
+	// run on the group timeout, before the deps are settled
+	var opts = Opts(WithDep, WithTimeout(20*time.Millisecond))
+	var audited atomic.Bool
+	release := make(chan token)
+	defer close(release)
+	err := Go(ctx, opts,
+		MakeRunner(func() error { <-release; return nil }).Name(opts, "a"),
+		MakeContextRunner(opts, func(ctx context.Context) error {
+			_, ok := ctx.Deadline() // own deadline
+			audited.Store(ok && ctx.Err() == nil)
+			return nil
+		}).Name(opts, "audit").Dep(opts, "a").Finally(opts))
+	assert.EqualError(t, err, "group timeout")
+	assert.True(t, audited.Load())
+
+	// named after Finally, Tolerant & Compensate
+	opts = Opts(WithDep)
+	var order []string
+	var mu sync.Mutex
+	record := func(name string, err error) func() error {
+		return func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return err
+		}
+	}
+	err = Go(ctx, opts,
+		MakeRunner(record("y", nil)).Compensate(opts, func(context.Context) error { return nil }).Name(opts, "y"),
+		MakeRunner(record("x", errors.New("x"))).Tolerant(opts).Name(opts, "x").Dep(opts, "y"),
+		MakeRunner(record("z", nil)).Name(opts, "z").Dep(opts, "x", "y"),
+		MakeRunner(record("cleanup", nil)).Finally(opts).Name(opts, "cleanup").Dep(opts, "z"))
+	var ce *CompensationError
+	assert.ErrorAs(t, err, &ce)
+	assert.Equal(t, []string{"y"}, ce.RolledBack)
+	assert.Equal(t, []string{"y", "x", "z", "cleanup"}, order) // x tolerated
+	assert.Equal(t, []string{"cleanup", "x", "y", "z"}, opts.Graph().Nodes())
+}
+
+func TestGroupGoCompensate(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	for _, serial := range []bool{false, true} {
+		var opts = Opts(WithDep)
+		if serial {
+			opts = Opts(WithDep, WithSerial)
+		}
+		var mu sync.Mutex
+		var undone []string
+		undo := func(name string, err error) func(context.Context) error {
+			return func(ctx context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				undone = append(undone, name)
+				return err
+			}
+		}
+		err := Go(ctx, opts,
+			MakeRunner(func() error { return nil }).Name(opts, "a").Compensate(opts, undo("a", nil)),
+			MakeRunner(func() error { return nil }).Name(opts, "b").Dep(opts, "a").Compensate(opts, undo("b", nil)),
+			MakeRunner(func() error { return nil }).Name(opts, "d").Compensate(opts, undo("d", errors.New("undo d"))),
+			MakeRunner(func() error { return errors.New("c") }).Name(opts, "c").Dep(opts, "b", "d").Compensate(opts, undo("c", nil)))
+		var ce *CompensationError
+		assert.ErrorAs(t, err, &ce)
+		assert.EqualError(t, ce.Err, "c")
+		// dependents first, the failed runner is not compensated
+		assert.Equal(t, []string{"b", "a", "d"}, undone)
+		assert.Equal(t, []string{"b", "a"}, ce.RolledBack)
+		assert.EqualError(t, ce.Failed["d"], "undo d")
+		assert.EqualError(t, err, "c (rolled back: b, a); compensation of d failed: undo d")
+	}
+
+	// not compensated if the group succeeded
+	var opts = Opts(WithDep)
+	var undone bool
+	assert.Nil(t, Go(ctx, opts, MakeRunner(func() error { return nil }).Name(opts, "a").Compensate(opts, func(context.Context) error {
+		undone = true
+		return nil
+	})))
+	assert.False(t, undone)
+
+	// a runner succeeded after the timeout is compensated as well
+	opts = Opts(WithDep, WithTimeout(50*time.Millisecond))
+	var shipped, cancelled atomic.Bool
+	err := Go(ctx, opts,
+		MakeRunner(func() error { return nil }).Name(opts, "debit").Compensate(opts, func(context.Context) error { return nil }),
+		MakeRunner(func() error {
+			time.Sleep(150 * time.Millisecond) // ignores the ctx
+			shipped.Store(true)
+			return nil
+		}).Name(opts, "ship").Dep(opts, "debit").Compensate(opts, func(context.Context) error {
+			cancelled.Store(true)
+			return nil
+		}))
+	var ce *CompensationError
+	assert.ErrorAs(t, err, &ce)
+	assert.EqualError(t, ce.Err, "group timeout")
+	assert.Equal(t, []string{"ship", "debit"}, ce.RolledBack)
+	assert.True(t, shipped.Load())
+	assert.True(t, cancelled.Load())
+}
+
+func TestGroupGoSoftDep(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	for _, serial := range []bool{false, true} {
+		var opts = Opts(WithDep)
+		if serial {
+			opts = Opts(WithDep, WithSerial)
+		}
+		var a atomic.Bool
+		var waited bool
+		err := Go(ctx, opts,
+			MakeRunner(func() error { time.Sleep(10 * time.Millisecond); a.Store(true); return nil }).Name(opts, "a"),
+			// "b" is feature-flagged off
+			MakeRunner(func() error { waited = a.Load(); return nil }).Name(opts, "c").SoftDep(opts, "a", "b"))
+		assert.Nil(t, err)
+		assert.True(t, waited)
+		assert.Nil(t, opts.ValidateDep())
+		assert.Equal(t, []string{"a"}, opts.Graph().Deps("c"))
+	}
+
+	// required deps are still verified
+	var opts = Opts(WithDep)
+	MakeRunner(func() error { return nil }).Name(opts, "c").SoftDep(opts, "b").Dep(opts, "d")
+	assert.EqualError(t, opts.ValidateDep(), `missing dependency "c" -> "d"`)
+}
+
+func TestGroupGoDepQuorum(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	for _, serial := range []bool{false, true} {
+		var opts = Opts(WithDep, WithJoinCancel)
+		if serial {
+			opts = Opts(WithDep, WithJoinCancel, WithSerial)
+		}
+		var merged atomic.Bool
+		slow := make(chan error, 1)
+		start := time.Now()
+		err := Go(ctx, opts,
+			MakeRunner(func() error { return errors.New("r1") }).Name(opts, "r1"),
+			MakeRunner(func() error { time.Sleep(10 * time.Millisecond); return nil }).Name(opts, "r2"),
+			MakeContextRunner(opts, func(ctx context.Context) error {
+				select {
+				case <-ctx.Done():
+					slow <- context.Cause(ctx)
+				case <-time.After(time.Second):
+					slow <- nil
+				}
+				return ctx.Err()
+			}).Name(opts, "r3"),
+			// the fastest replica
+			MakeRunner(func() error { merged.Store(true); return nil }).Name(opts, "merge").DepAny(opts, "r1", "r2", "r3"))
+		assert.Nil(t, err)
+		assert.True(t, merged.Load())
+		// r3 is no longer needed, cancelled or not run at all
+		if !serial {
+			assert.ErrorIs(t, <-slow, errUnneeded)
+		}
+		assert.Less(t, time.Since(start), time.Second)
+	}
+
+	// quorum not reached
+	var opts = Opts(WithDep)
+	var run bool
+	errA, errB := errors.New("a"), errors.New("b")
+	err := Go(ctx, opts,
+		MakeRunner(func() error { return errA }).Name(opts, "a"),
+		MakeRunner(func() error { return errB }).Name(opts, "b"),
+		MakeRunner(func() error { return nil }).Name(opts, "c"),
+		MakeRunner(func() error { run = true; return nil }).Name(opts, "read").DepQuorum(opts, 2, "a", "b", "c"))
+	assert.False(t, run)
+	assert.ErrorIs(t, err, errA)
+	assert.ErrorIs(t, err, errB)
+	assert.True(t, strings.HasPrefix(err.Error(), "quorum 2 of a, b, c not reached: "))
+}
+
+func TestGroupGoDepQuorumInvalid(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	// k out of 1..n is reported, not clamped
+	var opts = Opts(WithDep)
+	MakeRunner(func() error { return nil }).Name(opts, "a")
+	MakeRunner(func() error { return nil }).Name(opts, "b")
+	MakeRunner(func() error { return nil }).Name(opts, "x").DepQuorum(opts, 5, "a", "b")
+	MakeRunner(func() error { return nil }).Name(opts, "y").DepQuorum(opts, 0, "a", "b")
+	var verr *ValidationError
+	assert.ErrorAs(t, opts.ValidateDep(), &verr)
+	assert.Equal(t, []Problem{
+		{Kind: InvalidQuorum, Node: "x", Path: []string{"a", "b"}, K: 5},
+		{Kind: InvalidQuorum, Node: "y", Path: []string{"a", "b"}, K: 0},
+	}, verr.Problems)
+	assert.Equal(t, `invalid quorum 5 of "a", "b" on "x"`, verr.Problems[0].String())
+	assert.Panics(t, func() { MakeRunner(func() error { return nil }).Verify(opts) })
+	spec := &Spec{Nodes: []NodeSpec{{Name: "a"}, {Name: "b"}, {Name: "x", Joins: []JoinSpec{{K: 5, Deps: []string{"a", "b"}}}}}}
+	_, _, err := spec.Build(NewRegistry().Register("a", func() error { return nil }).Register("b", func() error { return nil }).Register("x", func() error { return nil }))
+	assert.EqualError(t, err, `invalid spec: invalid quorum 5 of "a", "b" on "x"`)
+
+	// the runner fails without running if not validated
+	for _, serial := range []bool{false, true} {
+		for _, k := range []int{5, 0} {
+			var opts = Opts(WithDep)
+			if serial {
+				opts = Opts(WithDep, WithSerial)
+			}
+			var run bool
+			err := Go(ctx, opts,
+				MakeRunner(func() error { return nil }).Name(opts, "a"),
+				MakeRunner(func() error { return nil }).Name(opts, "b"),
+				MakeRunner(func() error { run = true; return nil }).Name(opts, "x").DepQuorum(opts, k, "a", "b"))
+			assert.EqualError(t, err, fmt.Sprintf("invalid quorum %d of a, b", k))
+			assert.False(t, run)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"reflect"
 	"runtime"
+	"sync"
 	"time"
 	"unsafe"
 )
@@ -33,35 +34,72 @@ func filter[T any](s []T, f func(T) bool) []T {
 	return r
 }
 
+// messages are only formatted if the level is enabled
 func groupMonitor(ctx context.Context, method, prefix string, start time.Time, log bool, err error) {
-	if log {
-		slog.InfoContext(ctx, fmt.Sprintf("[Group %s] group %s done", method, prefix), slog.Duration("time_to_go", time.Since(start)))
-		if err != nil {
-			slog.ErrorContext(ctx, fmt.Sprintf("[Group %s] group %s failed", method, prefix), slog.String("err", err.Error()))
-		}
+	if !log {
+		return
+	}
+	if logger := slog.Default(); logger.Enabled(ctx, slog.LevelInfo) {
+		logger.InfoContext(ctx, fmt.Sprintf("[Group %s] group %s done", method, prefix), slog.Duration("time_to_go", time.Since(start)))
+	}
+	if logger := slog.Default(); err != nil && logger.Enabled(ctx, slog.LevelError) {
+		logger.ErrorContext(ctx, fmt.Sprintf("[Group %s] group %s failed", method, prefix), slog.String("err", err.Error()))
 	}
 }
 
-func funcMonitor(ctx context.Context, method, prefix, name string, start time.Time, log bool, errC chan error, err error) {
+// name of the runner is resolved from label and id only if needed
+func funcMonitor(ctx context.Context, method, prefix, label string, id func() error, start time.Time, log bool, sink ErrorSink, err error) {
 	if log {
-		slog.InfoContext(ctx, fmt.Sprintf("[Group %s] group %s: %s done", method, prefix, name), slog.Duration("time_to_go", time.Since(start)))
-		if err != nil {
-			slog.ErrorContext(ctx, fmt.Sprintf("[Group %s] group %s: %s failed", method, prefix, name), slog.String("err", err.Error()))
+		if logger := slog.Default(); logger.Enabled(ctx, slog.LevelInfo) {
+			logger.InfoContext(ctx, fmt.Sprintf("[Group %s] group %s: %s done", method, prefix, runnerName(label, id)), slog.Duration("time_to_go", time.Since(start)))
+		}
+		if logger := slog.Default(); err != nil && logger.Enabled(ctx, slog.LevelError) {
+			logger.ErrorContext(ctx, fmt.Sprintf("[Group %s] group %s: %s failed", method, prefix, runnerName(label, id)), slog.String("err", err.Error()))
 		}
 	}
-	if errC != nil && err != nil {
-		errC <- fmt.Errorf("%s failed: %w", name, err)
+	if sink != nil && err != nil {
+		sink.Put(fmt.Errorf("%s failed: %w", runnerName(label, id), err))
 	}
 }
 
+func timeoutMonitor(ctx context.Context, method, prefix string, after time.Duration) {
+	if logger := slog.Default(); logger.Enabled(ctx, slog.LevelInfo) {
+		logger.InfoContext(ctx, fmt.Sprintf("[Group %s] group %s timeout", method, prefix), slog.Duration("after", after))
+	}
+}
+
+// returns the runner name, or the func name of id for anonymous runners and funcs without deps
+func runnerName(label string, id func() error) string {
+	if label != "" {
+		return label
+	}
+	return funcName(id)
+}
+
+// func names by entry pc, resolved once
+var names = struct {
+	sync.RWMutex
+	m map[uintptr]string
+}{m: make(map[uintptr]string)}
+
 func funcName(f any) string {
 	v := reflect.ValueOf(f)
 	if v.Kind() != reflect.Func {
 		return "<not a func>"
 	}
-	fn := runtime.FuncForPC(v.Pointer())
-	if fn == nil {
-		return "<unknown>"
+	pc := v.Pointer()
+	names.RLock()
+	name, ok := names.m[pc]
+	names.RUnlock()
+	if ok {
+		return name
+	}
+	name = "<unknown>"
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		name = fn.Name()
 	}
-	return fn.Name()
+	names.Lock()
+	names.m[pc] = name
+	names.Unlock()
+	return name
 }
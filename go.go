@@ -3,8 +3,6 @@ package group
 import (
 	"context"
 	"errors"
-	"fmt"
-	"log/slog"
 	"time"
 
 	"golang.org/x/sync/errgroup"
@@ -26,51 +24,71 @@ func Go(ctx context.Context, opts *Options, fs ...func() error) (err error) {
 	if 0 < opts.Limit && opts.Limit < len(opts.dep) {
 		return errors.New("limit cannot be less than the number of funcs with deps")
 	}
+	if opts.stress != nil {
+		_, err = stressGo(ctx, opts, false, fs...)
+		return err
+	}
 	if opts.Prefix == "" {
 		opts.Prefix = "anonymous"
 	}
 	if opts.WithLog {
 		defer func(start time.Time) {
-			groupMonitor(ctx, fmt.Sprintf("Go%s", cond(opts.dep != nil, " | Dep", "")), opts.Prefix, start, opts.WithLog, err)
+			groupMonitor(ctx, cond(opts.dep != nil, "Go | Dep", "Go"), opts.Prefix, start, opts.WithLog, err)
 		}(time.Now())
 	}
+	if opts = opts.observe(fs); opts.obs != nil {
+		defer opts.obs.close()
+	}
 
-	g, gtx := errgroup.WithContext(ctx)
-	g.SetLimit(cond(opts.Limit > 0, opts.Limit, len(fs))) // limit defaults to number of funcs
 	// set timeout for group and fs
+	tctx := ctx
 	if opts.Timeout > 0 {
 		var cancel context.CancelFunc
-		gtx, cancel = context.WithTimeout(gtx, opts.Timeout)
+		tctx, cancel = timeoutContext(ctx, opts.clock, opts.Timeout)
 		defer cancel()
 	}
+	if len(opts.ctxf) > 0 {
+		tctx = withStore(tctx) // per-run store
+	}
+	tctx, endTask := opts.task(tctx)
+	defer endTask()
+	if opts.sched != nil {
+		return serialGo(ctx, tctx, cond(opts.dep != nil, "Go | Dep | Serial", "Go | Serial"), opts, fs...)
+	}
+	g, gtx := withContext(tctx, opts.pool)
+	var run *depRun // nil without deps
 	if opts.dep == nil {
+		g.SetLimit(cond(opts.Limit > 0, opts.Limit, len(fs))) // limit defaults to number of funcs
 		groupGo(gtx, g, opts, fs...)
 	} else {
 		// go runners with deps
 		// separate ctx for tolerance control
-		opts.dep.groupGo(ctx, gtx, g, opts)
+		x, sem := limited(g, opts.Limit)
+		run = opts.deps().groupGo(tctx, gtx, g, sem, opts)
 		// go runners without deps
-		groupGo(gtx, g, opts, filter(fs, func(f func() error) bool { return opts.dep[fptr(f)] == nil })...)
+		groupGo(gtx, x, opts, filter(fs, func(f func() error) bool { return opts.dep[fptr(f)] == nil })...)
 	}
 
 	// outer timeout control
 	if opts.Timeout > 0 {
-		for {
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-gtx.Done(): // actual timeout
-				if errors.Is(gtx.Err(), context.DeadlineExceeded) {
-					if opts.WithLog {
-						slog.InfoContext(gtx, fmt.Sprintf("[Group Go%s] group %s timeout", cond(opts.dep != nil, " | Dep", ""), opts.Prefix), slog.Duration("after", opts.Timeout))
-					}
-					return errors.New("group timeout")
-				}
-				return g.Wait()
+		done := make(chan token)
+		go func() { _ = g.Wait(); close(done) }()
+		select {
+		case <-ctx.Done():
+		case <-gtx.Done(): // err or actual timeout
+		case <-done:
+		}
+		if ctx.Err() != nil {
+			return run.compensate(run.finish(ctx.Err()))
+		}
+		if errors.Is(tctx.Err(), context.DeadlineExceeded) || expired(tctx, opts.clock) {
+			if opts.WithLog {
+				timeoutMonitor(gtx, cond(opts.dep != nil, "Go | Dep", "Go"), opts.Prefix, opts.Timeout)
 			}
+			return run.compensate(run.finish(errors.New("group timeout")))
 		}
 	}
-	return g.Wait()
+	return run.compensate(run.join(g.Wait()))
 }
 
 func TryGo(ctx context.Context, opts *Options, fs ...func() error) (ok bool, err error) {
@@ -89,49 +107,77 @@ func TryGo(ctx context.Context, opts *Options, fs ...func() error) (ok bool, err
 	if 0 < opts.Limit && opts.Limit < len(opts.dep) {
 		return false, errors.New("limit cannot be less than the number of funcs with deps")
 	}
+	if opts.stress != nil {
+		return stressGo(ctx, opts, true, fs...)
+	}
 	if opts.Prefix == "" {
 		opts.Prefix = "anonymous"
 	}
 	if opts.WithLog {
 		defer func(start time.Time) {
-			groupMonitor(ctx, fmt.Sprintf("TryGo%s", cond(opts.dep != nil, " | Dep", "")), opts.Prefix, start, opts.WithLog, err)
+			groupMonitor(ctx, cond(opts.dep != nil, "TryGo | Dep", "TryGo"), opts.Prefix, start, opts.WithLog, err)
 		}(time.Now())
 	}
+	if opts = opts.observe(fs); opts.obs != nil {
+		defer opts.obs.close()
+	}
 
-	g, gtx := errgroup.WithContext(ctx)
-	g.SetLimit(cond(opts.Limit > 0, opts.Limit, len(fs))) // limit defaults to the number of funcs
 	// set timeout for group and fs
+	tctx := ctx
 	if opts.Timeout > 0 {
 		var cancel context.CancelFunc
-		gtx, cancel = context.WithTimeout(gtx, opts.Timeout)
+		tctx, cancel = timeoutContext(ctx, opts.clock, opts.Timeout)
 		defer cancel()
 	}
+	if len(opts.ctxf) > 0 {
+		tctx = withStore(tctx) // per-run store
+	}
+	tctx, endTask := opts.task(tctx)
+	defer endTask()
+	if opts.sched != nil {
+		return true, serialGo(ctx, tctx, cond(opts.dep != nil, "TryGo | Dep | Serial", "TryGo | Serial"), opts, fs...)
+	}
+	g, gtx := withContext(tctx, opts.pool)
+	var run *depRun // nil without deps
 	if opts.dep == nil {
+		g.SetLimit(cond(opts.Limit > 0, opts.Limit, len(fs))) // limit defaults to the number of funcs
 		ok = groupTryGo(gtx, g, opts, fs...)
 	} else {
-		// go runners with deps
+		// go runners with deps, their slots are reserved
 		// separate ctx for tolerance control
-		ok = opts.dep.groupTryGo(ctx, gtx, g, opts)
+		x, sem := limited(g, opts.Limit)
+		run = opts.deps().groupGo(tctx, gtx, g, sem, opts)
 		// go runners without deps
-		ok = ok && groupTryGo(gtx, g, opts, filter(fs, func(r func() error) bool { return opts.dep[fptr(r)] == nil })...)
+		ok = groupTryGo(gtx, x, opts, filter(fs, func(r func() error) bool { return opts.dep[fptr(r)] == nil })...)
 	}
 
 	// outer timeout control
 	if opts.Timeout > 0 {
-		for {
-			select {
-			case <-ctx.Done():
-				return ok, ctx.Err()
-			case <-gtx.Done(): // actual timeout
-				if errors.Is(gtx.Err(), context.DeadlineExceeded) {
-					if opts.WithLog {
-						slog.InfoContext(gtx, fmt.Sprintf("[Group TryGo%s] group %s timeout", cond(opts.dep != nil, " | Dep", ""), opts.Prefix), slog.Duration("after", opts.Timeout))
-					}
-					return ok, errors.New("group timeout")
-				}
-				return ok, g.Wait()
+		done := make(chan token)
+		go func() { _ = g.Wait(); close(done) }()
+		select {
+		case <-ctx.Done():
+		case <-gtx.Done(): // err or actual timeout
+		case <-done:
+		}
+		if ctx.Err() != nil {
+			return ok, run.compensate(run.finish(ctx.Err()))
+		}
+		if errors.Is(tctx.Err(), context.DeadlineExceeded) || expired(tctx, opts.clock) {
+			if opts.WithLog {
+				timeoutMonitor(gtx, cond(opts.dep != nil, "TryGo | Dep", "TryGo"), opts.Prefix, opts.Timeout)
 			}
+			return ok, run.compensate(run.finish(errors.New("group timeout")))
 		}
 	}
-	return ok, g.Wait()
+	return ok, run.compensate(run.join(g.Wait()))
+}
+
+// returns the runner of the funcs without deps and the limit slots shared with the dep nodes, nil if not limited
+func limited(g egroup, limit int) (goer, chan token) {
+	if limit <= 0 {
+		return g, nil
+	}
+	sem := make(chan token, limit)
+	return reserved{egroup: g, sem: sem}, sem
 }
@@ -0,0 +1,143 @@
+package group
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Graph is a read-only snapshot of the dependency graph in Options
+// anonymous runners are labelled by func name and can't be dependent
+type Graph struct {
+	g     *depGraph
+	rdeps map[string][]string // node -> dependents
+	tol   map[string]token
+}
+
+// Graph returns a snapshot of the dependency graph, it is empty if dep is not enabled
+func (o *Options) Graph() *Graph {
+	var d depMap
+	if o != nil {
+		d = o.dep
+	}
+	g := &Graph{g: d.graph(), rdeps: make(map[string][]string, len(d))}
+	for _, node := range g.g.nodes {
+		for _, dep := range g.g.deps[node] {
+			g.rdeps[dep] = append(g.rdeps[dep], node)
+		}
+	}
+	g.tol = make(map[string]token)
+	for _, fd := range d {
+		if fd.tolerant && fd.deps[0] != "" {
+			g.tol[fd.deps[0]] = token{}
+		}
+	}
+	return g
+}
+
+// Nodes returns all nodes in sorted order
+func (g *Graph) Nodes() []string {
+	return slices.Clone(g.g.nodes)
+}
+
+// Has reports whether the node exists
+func (g *Graph) Has(name string) bool {
+	_, ok := g.g.deps[name]
+	return ok
+}
+
+// Deps returns the direct deps of the node
+func (g *Graph) Deps(name string) []string {
+	return slices.Clone(g.g.deps[name])
+}
+
+// Dependents returns the nodes that directly depend on the node
+func (g *Graph) Dependents(name string) []string {
+	return slices.Clone(g.rdeps[name])
+}
+
+// Ancestors returns all nodes the node transitively depends on in sorted order
+func (g *Graph) Ancestors(name string) []string {
+	return g.walk(name, g.g.deps)
+}
+
+// Descendants returns all nodes that transitively depend on the node in sorted order
+// i.e. the nodes affected if it fails
+func (g *Graph) Descendants(name string) []string {
+	return g.walk(name, g.rdeps)
+}
+
+// Roots returns the nodes without deps
+func (g *Graph) Roots() []string {
+	return filter(g.g.nodes, func(node string) bool { return len(g.g.deps[node]) == 0 })
+}
+
+// Sinks returns the nodes without dependents
+func (g *Graph) Sinks() []string {
+	return filter(g.g.nodes, func(node string) bool { return len(g.rdeps[node]) == 0 })
+}
+
+// Tolerant reports whether the node is marked as tolerant
+func (g *Graph) Tolerant(name string) bool {
+	_, ok := g.tol[name]
+	return ok
+}
+
+// TopoSort returns the nodes in topological order, deps first
+func (g *Graph) TopoSort() ([]string, error) {
+	waves, err := g.waves()
+	if err != nil {
+		return nil, err
+	}
+	return slices.Concat(waves...), nil
+}
+
+// dfs over edges, missing nodes are ignored
+func (g *Graph) walk(name string, edges map[string][]string) []string {
+	seen := make(map[string]token)
+	var dfs func(node string)
+	dfs = func(node string) {
+		for _, x := range edges[node] {
+			if _, ok := seen[x]; !ok && g.Has(x) {
+				seen[x] = token{}
+				dfs(x)
+			}
+		}
+	}
+	dfs(name)
+	delete(seen, name)
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// kahn by level, nodes in each wave are sorted
+func (g *Graph) waves() ([][]string, error) {
+	indeg, wave := make(map[string]int, len(g.g.nodes)), make([]string, 0)
+	for _, node := range g.g.nodes {
+		for _, dep := range g.g.deps[node] {
+			if !g.g.has(dep) {
+				return nil, fmt.Errorf("missing dependency %q", dep)
+			}
+		}
+		if indeg[node] = len(g.g.deps[node]); indeg[node] == 0 {
+			wave = append(wave, node)
+		}
+	}
+	var waves [][]string
+	for len(wave) > 0 {
+		var next []string
+		for _, node := range wave {
+			for _, x := range g.rdeps[node] {
+				if indeg[x]--; indeg[x] == 0 {
+					next = append(next, x)
+				}
+			}
+		}
+		slices.Sort(next)
+		waves, wave = append(waves, wave), next
+	}
+	if len(slices.Concat(waves...)) != len(g.g.nodes) {
+		return nil, errors.New("dependency cycle detected")
+	}
+	return waves, nil
+}
@@ -4,189 +4,572 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"iter"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	"golang.org/x/sync/errgroup"
 )
 
-func groupGo(ctx context.Context, g *errgroup.Group, opts *Options, fs ...func() error) {
-	for _, f := range fs {
-		g.Go(func() (err error) {
-			// ctx check before exec
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
-			}
-
-			// no opts short circuit
-			if opts == nil || !opts.WithLog && opts.ErrC == nil {
-				return SafeRun(ctx, f)
-			}
+// errgroup-like runner of the funcs
+type goer interface {
+	Go(f func() error)
+	TryGo(f func() error) bool
+}
 
-			if opts.WithLog || opts.ErrC != nil {
-				defer func(start time.Time) {
-					funcMonitor(ctx, "groupGo", opts.Prefix, funcName(f), start, opts.WithLog, opts.ErrC, err)
-				}(time.Now())
-			}
-			return SafeRun(ctx, f)
-		})
+func groupGo(ctx context.Context, g goer, opts *Options, fs ...func() error) {
+	for _, f := range fs {
+		opts.emit(Queued, "", f, nil)
+		g.Go(newCall(ctx, opts, "groupGo", f))
 	}
 }
 
-func groupTryGo(ctx context.Context, g *errgroup.Group, opts *Options, fs ...func() error) bool {
+func groupTryGo(ctx context.Context, g goer, opts *Options, fs ...func() error) bool {
 	ok := true
 	for _, f := range fs {
-		ok = ok && g.TryGo(func() (err error) {
-			// ctx check before exec
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
+		opts.emit(Queued, "", f, nil)
+		if ok = ok && g.TryGo(newCall(ctx, opts, "groupTryGo", f)); !ok {
+			opts.emit(Skipped, "", f, nil)
+		}
+	}
+	return ok
+}
+
+// call of a func without deps
+// calls are pooled with the bound exec, so no closure is allocated per call
+type call struct {
+	ctx    context.Context
+	opts   *Options
+	method string
+	f      func() error
+	exec   func() error
+}
+
+var calls sync.Pool
+
+func newCall(ctx context.Context, opts *Options, method string, f func() error) func() error {
+	c, _ := calls.Get().(*call)
+	if c == nil {
+		c = new(call)
+		c.exec = c.run
+	}
+	c.ctx, c.opts, c.method, c.f = ctx, opts, method, f
+	return c.exec
+}
+
+func (c *call) run() (err error) {
+	ctx, opts, method, f := c.ctx, c.opts, c.method, c.f
+	// exec is called once, recycle before run
+	c.ctx, c.opts, c.f = nil, nil, nil
+	calls.Put(c)
+
+	// ctx check before exec
+	select {
+	case <-ctx.Done():
+		opts.emit(Skipped, "", f, ctx.Err())
+		return ctx.Err()
+	default:
+	}
+	opts.emit(Started, "", f, nil)
+	defer func() { opts.emit(cond(err != nil, Failed, Finished), "", f, err) }()
+
+	// no opts short circuit
+	if opts == nil || !opts.WithLog && opts.errs() == nil {
+		return SafeRun(ctx, intercept(ctx, opts, "", f, opts.bind(f, ctx)))
+	}
+
+	defer func(start time.Time) {
+		funcMonitor(ctx, method, opts.Prefix, "", f, start, opts.WithLog, opts.errs(), err)
+	}(time.Now())
+	return SafeRun(ctx, intercept(ctx, opts, "", f, opts.bind(f, ctx)))
+}
+
+// dep node of a run
+type dnode struct {
+	fd        *fdep
+	pending   atomic.Int32 // unsettled deps, -1 if launched in a cycle
+	missing   string       // first missing dep
+	rdeps     []int32      // dependents
+	err       error        // err of the run, set before the dependents are launched
+	tolerated bool         // run on after the group failed, tolerated by the dependents
+	started   atomic.Bool  // launched, finalizers may be launched before the deps are settled
+	ready     func()       // ends the dep wait trace
+	ok        atomic.Bool  // exec succeeded, to be compensated if the group fails
+	settled   chan token   // closed once settled, for the nodes with compensation
+
+	joins    []*jstate               // joins of the deps, each is pending until decided
+	jdeps    []int32                 // joined deps
+	soft     bool                    // only joined by the dependents, its failure is not fatal by itself
+	mu       sync.Mutex              // guards cancel & unneeded
+	cancel   context.CancelCauseFunc // of the exec ctx, with WithJoinCancel
+	unneeded bool                    // no dependent needs it any more
+}
+
+// join of a run
+type jstate struct {
+	join
+	members  []int32
+	ok, fail atomic.Int32
+	decided  atomic.Bool
+
+	mu   sync.Mutex
+	errs []error // errors of the failed members
+}
+
+// settles a member, reports whether it decides the join
+func (js *jstate) settle(err error) bool {
+	if err == nil {
+		return (js.ok.Add(1) >= int32(js.k) || !js.valid()) && js.decided.CompareAndSwap(false, true)
+	}
+	js.mu.Lock()
+	js.errs = append(js.errs, err)
+	js.mu.Unlock()
+	return (js.fail.Add(1) > int32(len(js.members)-js.k) || !js.valid()) && js.decided.CompareAndSwap(false, true)
+}
+
+// returns the err of the decided join if no quorum is reached
+func (js *jstate) err() error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	return js.join.err(int(js.ok.Load()), js.errs)
+}
+
+var errUnneeded = errors.New("join decided, no longer needed")
+
+// counts the pending deps and sets up the joins
+func (n *dnode) setup(idx map[string]int32) {
+	for _, dep := range n.fd.deps[1:] {
+		if _, ok := idx[dep]; !ok {
+			if n.missing == "" {
+				n.missing = dep
 			}
+		} else if len(n.fd.joins) == 0 || !joined(n.fd, dep) {
+			n.pending.Add(1)
+		}
+	}
+	for _, jn := range n.fd.joins {
+		js := &jstate{join: jn}
+		for _, dep := range jn.deps {
+			if j, ok := idx[dep]; ok {
+				js.members = append(js.members, j)
+			}
+		}
+		// the missing members fail in exec
+		if len(js.members) > 0 {
+			n.pending.Add(1)
+		}
+		n.joins, n.jdeps = append(n.joins, js), append(n.jdeps, js.members...)
+	}
+	slices.Sort(n.jdeps)
+	n.jdeps = slices.Compact(n.jdeps)
+}
 
-			// no opts short circuit
-			if opts == nil || !opts.WithLog && opts.ErrC == nil {
-				return SafeRun(ctx, f)
+// dependency edges of the node, each joined dep once
+func (n *dnode) edges(idx map[string]int32) iter.Seq[int32] {
+	return func(yield func(int32) bool) {
+		for _, dep := range n.fd.deps[1:] {
+			if j, ok := idx[dep]; ok && (len(n.fd.joins) == 0 || !joined(n.fd, dep)) && !yield(j) {
+				return
+			}
+		}
+		for _, j := range n.jdeps {
+			if !yield(j) {
+				return
 			}
+		}
+	}
+}
 
-			if opts.WithLog || opts.ErrC != nil {
-				defer func(start time.Time) {
-					funcMonitor(ctx, "groupTryGo", opts.Prefix, funcName(f), start, opts.WithLog, opts.ErrC, err)
-				}(time.Now())
+// run of the dep nodes
+// a node is launched once all its deps are settled, no goroutine is parked on the deps
+type depRun struct {
+	d        depMap
+	ctx, gtx context.Context
+	g        egroup
+	sem      chan token // limit slots, one is reserved for each node until it is settled
+	opts     *Options
+	nodes    []dnode
+	idx      map[string]int32 // name -> node
+	live     atomic.Int32     // launched and not settled nodes
+	left     atomic.Int32     // not launched nodes
+
+	finals sync.WaitGroup // finalizers not done
+	mu     sync.Mutex
+	errs   []error // errors of the finalizers
+}
+
+// runs the runners with deps, ctx is the group ctx with timeout, gtx is the errgroup ctx for fast-fail
+// the returned run reports the errors of the finalizers
+func (d depMap) groupGo(ctx context.Context, gtx context.Context, g egroup, sem chan token, opts *Options) *depRun {
+	if len(d) == 0 {
+		return nil
+	}
+	run := &depRun{d: d, ctx: ctx, gtx: gtx, g: g, sem: sem, opts: opts, nodes: make([]dnode, 0, len(d))}
+	idx := make(map[string]int32, len(d))
+	run.idx = idx
+	for r := range d.keys(opts.shuffle) {
+		if d[r].deps[0] != "" {
+			idx[d[r].deps[0]] = int32(len(run.nodes))
+		}
+		run.nodes = append(run.nodes, dnode{fd: d[r]})
+	}
+	// dependents in one backing array, off[j] is the offset of the dependents of node j
+	off := make([]int32, 2*len(run.nodes)+1)
+	fill := off[len(run.nodes)+1:]
+	for i := range run.nodes {
+		run.nodes[i].setup(idx)
+		for j := range run.nodes[i].edges(idx) {
+			off[j+1]++
+		}
+	}
+	for j := range run.nodes {
+		off[j+1] += off[j]
+	}
+	rdeps := make([]int32, off[len(run.nodes)])
+	for i := range run.nodes {
+		for j := range run.nodes[i].edges(idx) {
+			rdeps[off[j]+fill[j]] = int32(i)
+			fill[j]++
+		}
+	}
+	only := d.joinOnly()
+	for j := range run.nodes {
+		run.nodes[j].rdeps = rdeps[off[j]:off[j+1]]
+		if name := run.nodes[j].fd.deps[0]; name != "" {
+			_, run.nodes[j].soft = only[name]
+		}
+		if run.nodes[j].fd.final {
+			run.finals.Add(1)
+		}
+		if run.nodes[j].fd.comp != nil {
+			run.nodes[j].settled = make(chan token)
+		}
+	}
+
+	if sem != nil {
+		for range run.nodes {
+			sem <- token{}
+		}
+	}
+	for i := range run.nodes {
+		opts.emit(Queued, run.nodes[i].fd.deps[0], run.nodes[i].fd.f, nil)
+		run.nodes[i].ready = opts.wait(ctx, run.nodes[i].fd.deps[0])
+	}
+	run.left.Store(int32(len(run.nodes)))
+	run.live.Add(1) // held by the setup
+	for i := range run.nodes {
+		if run.nodes[i].pending.Load() == 0 {
+			run.launch(int32(i))
+		}
+	}
+	run.done()
+	return run
+}
+
+func (run *depRun) launch(i int32) {
+	if !run.nodes[i].started.CompareAndSwap(false, true) {
+		return
+	}
+	run.left.Add(-1)
+	run.live.Add(1)
+	if !run.nodes[i].fd.final {
+		run.g.Go(func() error {
+			// the failure of a node only joined is decided by the joins
+			if err := run.exec(i); err != nil && !run.nodes[i].soft {
+				return err
 			}
-			return SafeRun(ctx, f)
+			return nil
 		})
+		return
 	}
-	return ok
+	// finalizer errors don't fail fast, joined to the group err
+	run.g.Go(func() error {
+		defer run.finals.Done()
+		if err := run.exec(i); err != nil {
+			run.mu.Lock()
+			run.errs = append(run.errs, err)
+			run.mu.Unlock()
+		}
+		return nil
+	})
 }
 
-func (d depMap) groupGo(ctx context.Context, gtx context.Context, g *errgroup.Group, opts *Options) {
-	var sigs = make(map[string]signal, len(d))
-	for _, fd := range d {
-		// skip anonymous func
-		if fd.deps[0] == "" {
-			continue
+// launches the finalizers not launched yet and waits for the finalizers, used when Go returns before the group is done
+// returns err joined with the errors of the finalizers
+func (run *depRun) finish(err error) error {
+	if run == nil {
+		return err
+	}
+	for i := range run.nodes {
+		if run.nodes[i].fd.final {
+			run.launch(int32(i))
 		}
-		// self signal
-		sigs[fd.deps[0]] = make(signal)
 	}
+	run.finals.Wait()
+	return run.join(err)
+}
 
-	for r := range d {
-		g.Go(func() (err error) {
-			// ctx check before exec
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-gtx.Done(): // early-stage err check before dep signal
-				return gtx.Err()
-			default:
-				defer notify(sigs[d[r].deps[0]])
-			}
+// returns err joined with the errors of the finalizers, err as is if none
+func (run *depRun) join(err error) error {
+	if run == nil {
+		return err
+	}
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	return joinFinal(err, run.errs)
+}
 
-			var depErr error // record dep err
-			for i, dep := range d[r].deps {
-				if i == 0 {
-					continue
-				}
-				if sigs[dep] == nil {
-					return fmt.Errorf("missing dep signal for %s", dep)
-				}
-				<-sigs[dep] // wait for dep signal
-				// ctx check after dep signal
-				select {
-				case <-ctx.Done():
-					return ctx.Err()
-				case <-gtx.Done():
-					// timeout is always fatal
-					if errors.Is(gtx.Err(), context.DeadlineExceeded) {
-						return gtx.Err()
-					}
-					// tolerance check
-					if _, ok := opts.tol[dep]; !ok {
-						return gtx.Err()
-					}
-					// propagate tolerance & record err
-					opts.tol[d[r].deps[0]], depErr = token{}, gtx.Err()
-				default: // ctx ok
-				}
-			}
+// runs the compensations of the succeeded runners if the group failed with err, see runner.Compensate
+// waits for the runners with compensation still running on timeout, a late success is compensated as well
+func (run *depRun) compensate(err error) error {
+	if run == nil || err == nil {
+		return err
+	}
+	var succeeded []*fdep
+	for i := range run.nodes {
+		// the ones launched after the group failed are skipped by the ctx check in exec
+		if run.nodes[i].settled != nil && run.nodes[i].started.Load() {
+			<-run.nodes[i].settled
+		}
+		if run.nodes[i].ok.Load() {
+			succeeded = append(succeeded, run.nodes[i].fd)
+		}
+	}
+	return compensate(run.ctx, run.opts, run.d, succeeded, err)
+}
 
-			if opts.WithLog || opts.ErrC != nil {
-				defer func(start time.Time) {
-					funcMonitor(ctx, "depMap.groupGo", opts.Prefix, cond(d[r].deps[0] != "", d[r].deps[0], funcName(d[r].f)), start, opts.WithLog, opts.ErrC, err)
-				}(time.Now())
-			}
-			if err = SafeRun(gtx, d[r].f); err != nil {
-				return cond(depErr != nil, fmt.Errorf("%v -> %w", depErr, err), err)
-			}
-			return depErr
-		})
+func joinFinal(err error, errs []error) error {
+	if len(errs) == 0 {
+		return err
+	}
+	return errors.Join(append([]error{err}, errs...)...)
+}
+
+// settles node i, launches the dependents that become ready
+func (run *depRun) settle(i int32, err error) {
+	run.nodes[i].err = err
+	if run.nodes[i].settled != nil {
+		close(run.nodes[i].settled)
+	}
+	for _, j := range run.nodes[i].rdeps {
+		if run.release(j, i, err) {
+			run.launch(j)
+		}
+	}
+	if run.sem != nil {
+		<-run.sem
+	}
+	run.done()
+}
+
+// settles the edge from the dependent j to node i, reports whether j becomes ready
+func (run *depRun) release(j, i int32, err error) bool {
+	n := &run.nodes[j]
+	if len(n.joins) == 0 || !slices.Contains(n.jdeps, i) {
+		return n.pending.Add(-1) == 0
 	}
+	var ready bool
+	for _, js := range n.joins {
+		if slices.Contains(js.members, i) && js.settle(err) {
+			run.prune(js)
+			ready = n.pending.Add(-1) == 0 || ready
+		}
+	}
+	return ready
 }
 
-func (d depMap) groupTryGo(ctx context.Context, gtx context.Context, g *errgroup.Group, opts *Options) bool {
-	var sigs = make(map[string]signal, len(d))
-	for _, fd := range d {
-		// skip anonymous func
-		if fd.deps[0] == "" {
+// cancels the members of the decided join no dependent needs any more, with WithJoinCancel
+func (run *depRun) prune(js *jstate) {
+	if !run.opts.joinCancel {
+		return
+	}
+	for _, m := range js.members {
+		x := &run.nodes[m]
+		if !x.soft || !all(x.rdeps, func(j int32) bool {
+			return all(run.nodes[j].joins, func(y *jstate) bool { return y.decided.Load() || !slices.Contains(y.members, m) })
+		}) {
 			continue
 		}
-		// self signal
-		sigs[fd.deps[0]] = make(signal)
+		x.mu.Lock()
+		if x.unneeded = true; x.cancel != nil {
+			x.cancel(errUnneeded)
+		}
+		x.mu.Unlock()
 	}
+}
 
-	ok := true
-	for r := range d {
-		ok = ok && g.TryGo(func() (err error) {
-			// ctx check before exec
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-gtx.Done(): // early-stage err check before dep signal
-				return gtx.Err()
-			default:
-				defer notify(sigs[d[r].deps[0]])
-			}
+// no node is live but some are not launched, the rest are in or behind a cycle
+func (run *depRun) done() {
+	if run.live.Add(-1) > 0 || run.left.Load() == 0 {
+		return
+	}
+	for i := range run.nodes {
+		if p := run.nodes[i].pending.Load(); p > 0 && run.nodes[i].pending.CompareAndSwap(p, -1) {
+			run.launch(int32(i))
+		}
+	}
+}
 
-			var depErr error // record dep err
-			for i, dep := range d[r].deps {
-				if i == 0 {
-					continue
-				}
-				if sigs[dep] == nil {
-					return fmt.Errorf("missing dep signal for %s", dep)
-				}
-				<-sigs[dep] // wait for dep signal
-				// ctx check after dep signal
-				select {
-				case <-ctx.Done():
-					return ctx.Err()
-				case <-gtx.Done():
-					// timeout is always fatal
-					if errors.Is(gtx.Err(), context.DeadlineExceeded) {
-						return gtx.Err()
-					}
-					// tolerance check
-					if _, ok := opts.tol[dep]; !ok {
-						return gtx.Err()
-					}
-					// propagate tolerance & record err
-					opts.tol[d[r].deps[0]], depErr = token{}, gtx.Err()
-				default: // ctx ok
-				}
-			}
+func (run *depRun) exec(i int32) (err error) {
+	n, opts, ctx, gtx := &run.nodes[i], run.opts, run.ctx, run.gtx
+	name := n.fd.deps[0]
+	defer func() { run.settle(i, err) }()
+	var ran bool // exec started
+	defer func() {
+		if !ran {
+			opts.emit(Skipped, name, n.fd.f, err)
+		}
+	}()
+	n.ready()
 
-			if opts.WithLog || opts.ErrC != nil {
-				defer func(start time.Time) {
-					funcMonitor(ctx, "depMap.groupTryGo", opts.Prefix, cond(d[r].deps[0] != "", d[r].deps[0], funcName(d[r].f)), start, opts.WithLog, opts.ErrC, err)
-				}(time.Now())
-			}
-			if err = SafeRun(gtx, d[r].f); err != nil {
-				return cond(depErr != nil, fmt.Errorf("%v -> %w", depErr, err), err)
+	if n.pending.Load() < 0 {
+		return errors.New("dependency cycle detected, no runner is ready")
+	}
+	if n.missing != "" {
+		return fmt.Errorf("missing dep signal for %s", n.missing)
+	}
+	if n.fd.final {
+		ran = true
+		return run.final(n)
+	}
+	var depErr error // record dep err
+	for _, dep := range n.fd.deps[1:] {
+		// joined deps are decided by the joins, they may not be settled
+		if len(n.joins) > 0 && joined(n.fd, dep) {
+			continue
+		}
+		if x := &run.nodes[run.idx[dep]]; x.err != nil {
+			depErr = x.err
+			break
+		}
+	}
+	// ctx check after the deps are settled
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	// a failed dep may be settled before the group ctx is cancelled
+	if gtx.Err() != nil || depErr != nil {
+		// the same err as the group if the dep failed, whichever is first
+		cause := cond(depErr != nil, depErr, gtx.Err())
+		// early-stage err check & timeout is always fatal
+		if len(n.fd.deps) == 1 || errors.Is(gtx.Err(), context.DeadlineExceeded) {
+			return cause
+		}
+		// tolerance check, the deps are settled before
+		if !tolerates(n.fd, func(dep string) (bool, bool) {
+			if len(n.joins) > 0 && joined(n.fd, dep) {
+				return false, false
 			}
-			return depErr
-		})
+			x := &run.nodes[run.idx[dep]]
+			return x.fd.tolerant || x.tolerated, x.err != nil
+		}) {
+			return cause
+		}
+		// propagate tolerance & record err
+		n.tolerated, depErr = true, cause
+	}
+	for _, js := range n.joins {
+		if err := js.err(); err != nil {
+			return err
+		}
+	}
+	rctx := cond(n.tolerated, ctx, gtx)
+	if n.soft && opts.joinCancel {
+		var cancel context.CancelCauseFunc
+		rctx, cancel = context.WithCancelCause(rctx)
+		defer cancel(nil)
+		n.mu.Lock()
+		n.cancel = cancel
+		unneeded := n.unneeded
+		n.mu.Unlock()
+		if unneeded {
+			return errUnneeded
+		}
+	}
+
+	if opts.WithLog || opts.errs() != nil {
+		defer func(start time.Time) {
+			funcMonitor(ctx, "depMap.groupGo", opts.Prefix, name, n.fd.f, start, opts.WithLog, opts.errs(), err)
+		}(time.Now())
+	}
+	ran = true
+	opts.emit(Started, name, n.fd.f, nil)
+	// tolerated runners and their interceptors are not cancelled by the dep err
+	err = SafeRun(rctx, intercept(rctx, opts, name, n.fd.f, bind(opts.dep, n.fd, rctx)))
+	opts.emit(cond(err != nil, Failed, Finished), name, n.fd.f, err)
+	n.ok.Store(err == nil)
+	if err != nil {
+		return cond(depErr != nil, fmt.Errorf("%v -> %w", depErr, err), err)
+	}
+	return depErr
+}
+
+// runs the finalizer on the ctx not cancelled by the group, with its own deadline
+// the deps may not be settled on the group timeout, their errors are not read
+func (run *depRun) final(n *dnode) (err error) {
+	opts, name := run.opts, n.fd.deps[0]
+	ctx, cancel := finalContext(run.ctx, opts)
+	defer cancel()
+	if opts.WithLog || opts.errs() != nil {
+		defer func(start time.Time) {
+			funcMonitor(ctx, "depMap.groupGo | Finally", opts.Prefix, name, n.fd.f, start, opts.WithLog, opts.errs(), err)
+		}(time.Now())
+	}
+	opts.emit(Started, name, n.fd.f, nil)
+	err = SafeRun(ctx, intercept(ctx, opts, name, n.fd.f, bind(opts.dep, n.fd, ctx)))
+	opts.emit(cond(err != nil, Failed, Finished), name, n.fd.f, err)
+	n.ok.Store(err == nil)
+	return err
+}
+
+// returns the ctx of the finalizers, not cancelled with ctx, with its own Timeout deadline
+func finalContext(ctx context.Context, opts *Options) (context.Context, context.CancelFunc) {
+	ctx = context.WithoutCancel(ctx)
+	if opts.Timeout > 0 {
+		return timeoutContext(ctx, opts.clock, opts.Timeout)
+	}
+	return ctx, nop
+}
+
+// errgroup with part of the limit slots taken by the dep nodes, the funcs without deps take the rest
+type reserved struct {
+	egroup
+	sem chan token
+}
+
+func (r reserved) Go(f func() error) {
+	r.sem <- token{}
+	r.egroup.Go(func() error {
+		defer func() { <-r.sem }()
+		return f()
+	})
+}
+
+func (r reserved) TryGo(f func() error) bool {
+	select {
+	case r.sem <- token{}:
+	default:
+		return false
+	}
+	r.egroup.Go(func() error {
+		defer func() { <-r.sem }()
+		return f()
+	})
+	return true
+}
+
+// reports whether the runner of fd runs on after the group failed: every failed dep is tolerated,
+// by the dep itself (Tolerant), the edge (DepTolerant) or propagation, and at least one dep is tolerated
+// dep reports whether the dep is tolerant (or propagated) and failed in the run
+func tolerates(fd *fdep, dep func(name string) (tolerant, failed bool)) bool {
+	var ok bool
+	for _, name := range fd.deps[1:] {
+		tolerant, failed := dep(name)
+		tolerant = tolerant || slices.Contains(fd.tol, name)
+		if failed && !tolerant {
+			return false
+		}
+		ok = ok || tolerant
 	}
 	return ok
 }
@@ -0,0 +1,173 @@
+// Package groupdebug serves the state of the groups in flight over http, like net/http/pprof
+//
+// Importing the package registers the handler at /debug/group/ on http.DefaultServeMux,
+// only the groups with group.WithDebug are tracked
+//
+//	import _ "github.com/oatcatx/group/groupdebug"
+package groupdebug
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/oatcatx/group"
+)
+
+func init() {
+	http.Handle("/debug/group/", Handler())
+}
+
+// Handler serves the runs in flight as html, or as json with ?format=json
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		runs := group.Inflight()
+		if r.URL.Query().Get("format") == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(jsonRuns(runs))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		now := time.Now()
+		views := make([]runView, 0, len(runs))
+		for _, run := range runs {
+			views = append(views, view(run, now))
+		}
+		if err := page.Execute(w, views); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+type jsonRun struct {
+	ID      uint64       `json:"id"`
+	Prefix  string       `json:"prefix"`
+	Start   time.Time    `json:"start"`
+	Limit   int          `json:"limit,omitempty"`
+	Timeout string       `json:"timeout,omitempty"`
+	Runners []jsonRunner `json:"runners"`
+}
+
+type jsonRunner struct {
+	Name    string    `json:"name"`
+	State   string    `json:"state"`
+	Since   time.Time `json:"since"`
+	Deps    []string  `json:"deps,omitempty"`
+	Waiting []string  `json:"waiting,omitempty"`
+	Err     string    `json:"err,omitempty"`
+}
+
+func jsonRuns(runs []group.RunState) []jsonRun {
+	js := make([]jsonRun, 0, len(runs))
+	for _, run := range runs {
+		j := jsonRun{ID: run.ID, Prefix: run.Prefix, Start: run.Start, Limit: run.Limit, Runners: make([]jsonRunner, 0, len(run.Runners))}
+		if run.Timeout > 0 {
+			j.Timeout = run.Timeout.String()
+		}
+		for _, x := range run.Runners {
+			jr := jsonRunner{Name: x.Name, State: x.State.String(), Since: x.Since, Deps: x.Deps, Waiting: x.Waiting}
+			if x.Err != nil {
+				jr.Err = x.Err.Error()
+			}
+			j.Runners = append(j.Runners, jr)
+		}
+		js = append(js, j)
+	}
+	return js
+}
+
+type runView struct {
+	group.RunState
+	Age    time.Duration
+	Levels [][]runnerView // runners by dep depth
+}
+
+type runnerView struct {
+	group.RunnerState
+	For string // time in the state
+}
+
+// lays out the runners by dep depth, runners in a dep cycle are put on the last level
+func view(run group.RunState, now time.Time) runView {
+	v := runView{RunState: run, Age: now.Sub(run.Start).Round(time.Millisecond)}
+	byName := make(map[string]group.RunnerState, len(run.Runners))
+	for _, x := range run.Runners {
+		byName[x.Name] = x
+	}
+	depth := make(map[string]int, len(run.Runners))
+	var walk func(name string, seen map[string]bool) int
+	walk = func(name string, seen map[string]bool) int {
+		if d, ok := depth[name]; ok {
+			return d
+		}
+		if seen[name] {
+			return len(run.Runners) // cycle
+		}
+		seen[name] = true
+		d := 0
+		for _, dep := range byName[name].Deps {
+			if _, ok := byName[dep]; ok {
+				d = max(d, walk(dep, seen)+1)
+			}
+		}
+		depth[name] = d
+		return d
+	}
+	for _, x := range run.Runners {
+		d := min(walk(x.Name, map[string]bool{}), len(run.Runners))
+		for len(v.Levels) <= d {
+			v.Levels = append(v.Levels, nil)
+		}
+		v.Levels[d] = append(v.Levels[d], runnerView{RunnerState: x, For: now.Sub(x.Since).Round(time.Millisecond).String()})
+	}
+	// drop the empty levels
+	levels := v.Levels[:0]
+	for _, l := range v.Levels {
+		if len(l) > 0 {
+			levels = append(levels, l)
+		}
+	}
+	v.Levels = levels
+	return v
+}
+
+var page = template.Must(template.New("group").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>/debug/group/</title>
+<style>
+body { font-family: monospace; }
+.run { margin-bottom: 2em; }
+.graph { display: flex; gap: 2em; align-items: flex-start; }
+.level { display: flex; flex-direction: column; gap: 0.5em; }
+.node { border: 1px solid #444; border-radius: 4px; padding: 0.3em 0.6em; min-width: 10em; }
+.queued { background: #eeeeee; }
+.started { background: #ffe08a; }
+.finished { background: #9be69b; }
+.failed { background: #f49a9a; }
+.skipped { background: #cfd8dc; color: #666; }
+.deps, .err { font-size: smaller; }
+</style>
+</head>
+<body>
+<p>{{len .}} group(s) in flight, <a href="?format=json">json</a></p>
+{{range .}}
+<div class="run">
+<h3>#{{.ID}} {{.Prefix}}</h3>
+<p>started {{.Start.Format "15:04:05.000"}} ({{.Age}} ago){{if .Limit}}, limit {{.Limit}}{{end}}{{if .Timeout}}, timeout {{.Timeout}}{{end}}</p>
+<div class="graph">
+{{range .Levels}}<div class="level">
+{{range .}}<div class="node {{.State}}">
+<b>{{.Name}}</b><br>
+{{if eq .State.String "queued"}}{{if .Waiting}}waiting on {{range $i, $d := .Waiting}}{{if $i}}, {{end}}{{$d}}{{end}}{{else}}waiting for a slot{{end}}{{else if eq .State.String "started"}}running for {{.For}}{{else}}{{.State}} {{.For}} ago{{end}}
+{{if .Deps}}<div class="deps">&larr; {{range $i, $d := .Deps}}{{if $i}}, {{end}}{{$d}}{{end}}</div>{{end}}
+{{if .Err}}<div class="err">{{.Err}}</div>{{end}}
+</div>
+{{end}}</div>
+{{end}}</div>
+</div>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,62 @@
+package groupdebug_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oatcatx/group"
+	"github.com/oatcatx/group/groupdebug"
+)
+
+func TestHandler(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var opts = group.Opts(group.WithDep, group.WithDebug, group.WithPrefix("debug"))
+	errC := make(chan error, 1)
+	go func() {
+		errC <- group.Go(context.Background(), opts,
+			group.MakeRunner(func() error { return nil }).Name(opts, "a"),
+			group.MakeRunner(func() error { close(started); <-release; return nil }).Name(opts, "b").Dep(opts, "a"),
+			group.MakeRunner(func() error { return nil }).Name(opts, "c").Dep(opts, "a", "b"))
+	}()
+	<-started
+
+	var runs []struct {
+		Prefix  string
+		Runners []struct {
+			Name    string
+			State   string
+			Waiting []string
+		}
+	}
+	// c may be queued after b started
+	assert.Eventually(t, func() bool {
+		w := httptest.NewRecorder()
+		groupdebug.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/debug/group/?format=json", nil))
+		runs = nil
+		return json.Unmarshal(w.Body.Bytes(), &runs) == nil && len(runs) == 1 && len(runs[0].Runners) == 3
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "debug", runs[0].Prefix)
+	states := map[string]string{}
+	for _, r := range runs[0].Runners {
+		states[r.Name] = r.State
+		if r.Name == "c" {
+			assert.Equal(t, []string{"b"}, r.Waiting)
+		}
+	}
+	assert.Equal(t, map[string]string{"a": "finished", "b": "started", "c": "queued"}, states)
+
+	w := httptest.NewRecorder()
+	groupdebug.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/debug/group/", nil))
+	assert.True(t, strings.Contains(w.Body.String(), `<div class="node started">`))
+	assert.True(t, strings.Contains(w.Body.String(), "waiting on b"))
+
+	close(release)
+	assert.Nil(t, <-errC)
+	assert.Empty(t, group.Inflight())
+}
@@ -0,0 +1,136 @@
+package grouptest
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Faults injects faults into the named runners as a group.Interceptor, e.g.
+//
+//	faults := grouptest.NewFaults(42)
+//	faults.Error("db", errors.New("conn reset")).Prob(0.5)
+//	faults.Hang("cache").OnCall(2)
+//	var opts = group.Opts(group.WithDep, group.WithInterceptor(faults.Intercept))
+//
+// panics are recovered by group.SafeRun as *group.PanicError
+type Faults struct {
+	mu     sync.Mutex
+	rng    *rand.Rand
+	faults map[string][]*Fault
+	calls  map[string]int
+}
+
+type faultKind int
+
+const (
+	faultError faultKind = iota
+	faultPanic
+	faultHang
+	faultLatency
+)
+
+// Fault is injected on every call by default
+type Fault struct {
+	kind    faultKind
+	err     error
+	value   any
+	latency time.Duration
+	prob    float64
+	nth     int
+}
+
+// NewFaults returns the injector, seed determines the probabilistic faults
+func NewFaults(seed uint64) *Faults {
+	return &Faults{rng: rand.New(rand.NewPCG(seed, seed)), faults: make(map[string][]*Fault), calls: make(map[string]int)}
+}
+
+// Error makes the runner return err without running it
+func (f *Faults) Error(name string, err error) *Fault {
+	return f.add(name, &Fault{kind: faultError, err: err})
+}
+
+// Panic makes the runner panic with v
+func (f *Faults) Panic(name string, v any) *Fault {
+	return f.add(name, &Fault{kind: faultPanic, value: v})
+}
+
+// Hang makes the runner block until its ctx is cancelled
+func (f *Faults) Hang(name string) *Fault {
+	return f.add(name, &Fault{kind: faultHang})
+}
+
+// Latency delays the runner by d
+func (f *Faults) Latency(name string, d time.Duration) *Fault {
+	return f.add(name, &Fault{kind: faultLatency, latency: d})
+}
+
+func (f *Faults) add(name string, x *Fault) *Fault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	x.prob = 1
+	f.faults[name] = append(f.faults[name], x)
+	return x
+}
+
+// Prob injects the fault with probability p
+func (x *Fault) Prob(p float64) *Fault {
+	x.prob = p
+	return x
+}
+
+// OnCall injects the fault on the nth call only, starting from 1
+func (x *Fault) OnCall(n int) *Fault {
+	x.nth = n
+	return x
+}
+
+// Calls returns the number of calls of the runner
+func (f *Faults) Calls(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[name]
+}
+
+// returns the faults to inject for this call
+func (f *Faults) hit(name string) []*Fault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[name]++
+	var hits []*Fault
+	for _, x := range f.faults[name] {
+		if x.nth > 0 && x.nth != f.calls[name] {
+			continue
+		}
+		if x.prob < 1 && f.rng.Float64() >= x.prob {
+			continue
+		}
+		hits = append(hits, x)
+	}
+	return hits
+}
+
+// Intercept is the group.Interceptor, faults are injected in the order they are added
+func (f *Faults) Intercept(ctx context.Context, name string, fn func() error) error {
+	for _, x := range f.hit(name) {
+		switch x.kind {
+		case faultError:
+			return x.err
+		case faultPanic:
+			panic(x.value)
+		case faultHang:
+			<-ctx.Done()
+			return ctx.Err()
+		case faultLatency:
+			t := time.NewTimer(x.latency)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return ctx.Err()
+			case <-t.C:
+			}
+		}
+	}
+	return fn()
+}
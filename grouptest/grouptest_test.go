@@ -0,0 +1,125 @@
+package grouptest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oatcatx/group"
+	"github.com/oatcatx/group/grouptest"
+)
+
+func nop() error { return nil }
+
+func graph(opts *group.Options, fail string) []func() error {
+	run := func(name string) func() error {
+		return func() error { return cond(name == fail, errors.New(name), nil) }
+	}
+	return []func() error{
+		group.MakeRunner(run("a")).Name(opts, "a"),
+		group.MakeRunner(run("b")).Name(opts, "b").Dep(opts, "a"),
+		group.MakeRunner(run("c")).Name(opts, "c").Dep(opts, "a"),
+		group.MakeRunner(run("d")).Name(opts, "d").Dep(opts, "b", "c"),
+	}
+}
+
+func TestSeeded(t *testing.T) {
+	t.Parallel()
+
+	var orders [][]string
+	for range 2 {
+		rec := grouptest.NewRecorder()
+		var opts = group.Opts(group.WithDep, group.WithScheduler(grouptest.Seeded(42)), group.WithInterceptor(rec.Intercept))
+		assert.Nil(t, group.Go(context.Background(), opts, graph(opts, "")...))
+		rec.RanBefore(t, "a", "b")
+		rec.RanBefore(t, "c", "d")
+		rec.MaxConcurrency(t, 1)
+		orders = append(orders, rec.Order())
+	}
+	assert.Equal(t, orders[0], orders[1])
+}
+
+func TestOrdered(t *testing.T) {
+	t.Parallel()
+
+	rec := grouptest.NewRecorder()
+	var opts = group.Opts(group.WithDep, group.WithScheduler(grouptest.Ordered("c", "b")), group.WithInterceptor(rec.Intercept))
+	err := group.Go(context.Background(), opts, graph(opts, "c")...)
+
+	assert.EqualError(t, err, "c")
+	assert.Equal(t, []string{"a", "c"}, rec.Order())
+	rec.Failed(t, "c")
+	rec.Skipped(t, "b", "d")
+}
+
+func TestFakeClock(t *testing.T) {
+	t.Parallel()
+
+	clock := grouptest.NewFakeClock(time.Now())
+	var opts = group.Opts(group.WithClock(clock), group.WithTimeout(time.Hour))
+	err := group.Go(context.Background(), opts, nop, func() error {
+		clock.Advance(2 * time.Hour) // slow func
+		return nil
+	})
+
+	assert.EqualError(t, err, "group timeout")
+	assert.Equal(t, 0, clock.Timers())
+}
+
+func cond[T any](cond bool, t, f T) T {
+	if cond {
+		return t
+	}
+	return f
+}
+
+func TestFakeClockNoTimeout(t *testing.T) {
+	t.Parallel()
+
+	clock := grouptest.NewFakeClock(time.Now())
+	var opts = group.Opts(group.WithClock(clock), group.WithTimeout(time.Hour))
+	err := group.Go(context.Background(), opts, nop, func() error {
+		clock.Advance(30 * time.Minute)
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, clock.Timers())
+}
+
+func TestFaults(t *testing.T) {
+	t.Parallel()
+
+	var ctx = context.Background()
+	faults, rec := grouptest.NewFaults(1), grouptest.NewRecorder()
+	faults.Latency("a", 10*time.Millisecond)
+	faults.Error("a", errors.New("a down"))
+	var opts = group.Opts(group.WithDep, group.WithInterceptor(rec.Intercept, faults.Intercept))
+	err := group.Go(ctx, opts,
+		group.MakeRunner(nop).Name(opts, "a").Tolerant(opts),
+		group.MakeRunner(func() error { return nil }).Name(opts, "b").Dep(opts, "a"))
+
+	assert.EqualError(t, err, "a down")
+	rec.Failed(t, "a")
+	rec.RanBefore(t, "a", "b") // degraded
+
+	// panic on the 2nd call
+	faults = grouptest.NewFaults(1)
+	faults.Panic("p", "boom").OnCall(2)
+	opts = group.Opts(group.WithDep, group.WithInterceptor(faults.Intercept))
+	p := group.MakeRunner(nop).Name(opts, "p")
+	assert.Nil(t, group.Go(ctx, opts, p))
+	var perr *group.PanicError
+	assert.ErrorAs(t, group.Go(ctx, opts, p), &perr)
+	assert.Equal(t, "boom", perr.Value)
+	assert.Equal(t, 2, faults.Calls("p"))
+
+	// hang until timeout
+	faults = grouptest.NewFaults(1)
+	faults.Hang("h")
+	opts = group.Opts(group.WithDep, group.WithTimeout(10*time.Millisecond), group.WithInterceptor(faults.Intercept))
+	assert.EqualError(t, group.Go(ctx, opts, group.MakeRunner(nop).Name(opts, "h")), "group timeout")
+}
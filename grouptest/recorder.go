@@ -0,0 +1,141 @@
+package grouptest
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// Recorder records the runs of the runners as a group.Interceptor, e.g.
+//
+//	rec := grouptest.NewRecorder()
+//	var opts = group.Opts(group.WithDep, group.WithInterceptor(rec.Intercept))
+//	_ = group.Go(ctx, opts, ...)
+//	rec.RanBefore(t, "a", "b")
+type Recorder struct {
+	mu            sync.Mutex
+	events        []Event
+	errs          map[string]error
+	running, peak int
+}
+
+// Event is a start or end of a runner
+type Event struct {
+	Name  string
+	Start bool
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{errs: make(map[string]error)}
+}
+
+// Intercept is the group.Interceptor
+func (r *Recorder) Intercept(ctx context.Context, name string, f func() error) (err error) {
+	r.mu.Lock()
+	r.events = append(r.events, Event{Name: name, Start: true})
+	r.running++
+	r.peak = max(r.peak, r.running)
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = append(r.events, Event{Name: name})
+		r.running--
+		r.errs[name] = err
+	}()
+	return f()
+}
+
+// Events returns the recorded events in order
+func (r *Recorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.events)
+}
+
+// Order returns the runners in start order
+func (r *Recorder) Order() []string {
+	var order []string
+	for _, e := range r.Events() {
+		if e.Start {
+			order = append(order, e.Name)
+		}
+	}
+	return order
+}
+
+// Peak returns the max number of runners running at the same time
+func (r *Recorder) Peak() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.peak
+}
+
+// Err returns the err of the finished runner
+func (r *Recorder) Err(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.errs[name]
+}
+
+func (r *Recorder) index(name string, start bool) int {
+	return slices.IndexFunc(r.Events(), func(e Event) bool { return e.Name == name && e.Start == start })
+}
+
+// RanBefore asserts that a finished before b started
+func (r *Recorder) RanBefore(t testing.TB, a, b string) bool {
+	t.Helper()
+	end, start := r.index(a, false), r.index(b, true)
+	if end < 0 || start < 0 || end > start {
+		t.Errorf("expected %q to run before %q, got order %q", a, b, r.Order())
+		return false
+	}
+	return true
+}
+
+// Ran asserts that the runners have run
+func (r *Recorder) Ran(t testing.TB, names ...string) bool {
+	t.Helper()
+	for _, name := range names {
+		if r.index(name, true) < 0 {
+			t.Errorf("expected %q to run, got order %q", name, r.Order())
+			return false
+		}
+	}
+	return true
+}
+
+// Skipped asserts that the runners have never run
+func (r *Recorder) Skipped(t testing.TB, names ...string) bool {
+	t.Helper()
+	for _, name := range names {
+		if r.index(name, true) >= 0 {
+			t.Errorf("expected %q to be skipped, got order %q", name, r.Order())
+			return false
+		}
+	}
+	return true
+}
+
+// Failed asserts that the runners have returned errs
+func (r *Recorder) Failed(t testing.TB, names ...string) bool {
+	t.Helper()
+	for _, name := range names {
+		if r.Err(name) == nil {
+			t.Errorf("expected %q to fail", name)
+			return false
+		}
+	}
+	return true
+}
+
+// MaxConcurrency asserts that at most n runners have run at the same time
+func (r *Recorder) MaxConcurrency(t testing.TB, n int) bool {
+	t.Helper()
+	if peak := r.Peak(); peak > n {
+		t.Errorf("expected max concurrency %d, got %d", n, peak)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package grouptest
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"github.com/oatcatx/group"
+)
+
+// Seeded picks the ready runners in a pseudo-random order, the same seed gives the same order
+func Seeded(seed uint64) group.Scheduler {
+	return &seeded{r: rand.New(rand.NewPCG(seed, seed))}
+}
+
+type seeded struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (s *seeded) Next(ready []string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.IntN(len(ready))
+}
+
+// Ordered picks the ready runners in the order of names, the runners not in names are picked first come first served
+func Ordered(names ...string) group.Scheduler {
+	return ordered(names)
+}
+
+type ordered []string
+
+func (o ordered) Next(ready []string) int {
+	best, rank := 0, len(o)
+	for i, name := range ready {
+		if x := slices.Index(o, name); x >= 0 && x < rank {
+			best, rank = i, x
+		}
+	}
+	return best
+}
@@ -0,0 +1,28 @@
+package group
+
+import (
+	"context"
+	"slices"
+)
+
+// Interceptor wraps the execution of each runner, e.g. grouptest.Recorder
+// name is the runner name, or the func name for anonymous runners and funcs without deps
+// interceptors run inside the panic recovery, the first one is the outermost
+type Interceptor func(ctx context.Context, name string, f func() error) error
+
+// chains the interceptors around f, label is the runner name if any, id is the func named after otherwise
+func intercept(ctx context.Context, opts *Options, label string, id, f func() error) func() error {
+	if opts == nil || len(opts.icpt) == 0 && !opts.trace {
+		return f
+	}
+	name := runnerName(label, id)
+	for _, icpt := range slices.Backward(opts.icpt) {
+		next := f
+		f = func() error { return icpt(ctx, name, next) }
+	}
+	if opts.trace {
+		next := f
+		f = func() error { return traceRunner(ctx, opts.Prefix, name, next) }
+	}
+	return f
+}
@@ -1,8 +1,9 @@
 package group
 
 import (
-	"errors"
+	"context"
 	"log/slog"
+	"math/rand/v2"
 	"time"
 )
 
@@ -12,11 +13,26 @@ type Options struct {
 	Prefix  string        // group name, used for log, default is "anonymous"
 	Limit   int           // concurrency limit
 	Timeout time.Duration // group timeout
-	ErrC    chan error    // error collector
+	ErrC    chan error    // error collector, blocks the runners until received, see WithErrorSink
 	WithLog bool
 
-	dep depMap           // dependency map
-	tol map[string]token // tolerance map
+	dep        depMap                                  // dependency map
+	reduce     bool                                    // drop redundant dep waits
+	joinCancel bool                                    // cancel the joined deps no longer needed
+	ctxf       map[uintptr]func(context.Context) error // context-aware funcs of the runners
+
+	clock   Clock         // timeout clock
+	sched   Scheduler     // serial scheduler
+	icpt    []Interceptor // runner interceptors
+	stress  *stress       // stress mode
+	shuffle *rand.Rand    // start order shuffler
+
+	progress *progressOpt // progress publisher
+	obs      observers    // observers of the run
+	debug    bool         // tracked in flight
+	trace    bool         // pprof labels & trace regions
+	pool     *Pool        // worker pool
+	sink     ErrorSink    // error sink
 }
 
 func Opts(opts ...option) *Options {
@@ -31,6 +47,12 @@ func WithPrefix(s string) option                { return func(o *Options) { o.Pr
 func WithLimit(x int) option                    { return func(o *Options) { o.Limit = x } }
 func WithTimeout(t time.Duration) option        { return func(o *Options) { o.Timeout = t } }
 func WithErrorCollector(errC chan error) option { return func(o *Options) { o.ErrC = errC } }
+func WithClock(c Clock) option                  { return func(o *Options) { o.clock = c } }
+func WithScheduler(s Scheduler) option          { return func(o *Options) { o.sched = s } }
+func WithPool(p *Pool) option                   { return func(o *Options) { o.pool = p } }
+func WithInterceptor(is ...Interceptor) option {
+	return func(o *Options) { o.icpt = append(o.icpt, is...) }
+}
 func WithLogger(logger *slog.Logger) option {
 	return func(o *Options) { o.WithLog = true; slog.SetDefault(logger) }
 }
@@ -38,12 +60,34 @@ func WithLogger(logger *slog.Logger) option {
 var (
 	WithLog option = func(o *Options) { o.WithLog = true }
 	WithDep option = func(o *Options) { o.dep = make(depMap) }
+	// drops the redundant dep waits at run time, see Graph.Redundant
+	WithReduce option = func(o *Options) { o.reduce = true }
+	// runs the funcs one at a time on the caller goroutine in a stable topological order, for debugging
+	WithSerial option = func(o *Options) { o.sched = serial{} }
+	// tracks the runs in flight for debugging, see Inflight and groupdebug.Handler
+	WithDebug option = func(o *Options) { o.debug = true }
+	// labels the runners with pprof labels group and runner, and traces the runs as runtime/trace tasks,
+	// the runners as regions and the dep waits as tasks
+	WithTrace option = func(o *Options) { o.trace = true }
+	// cancels the runners only joined by their dependents once the joins are decided, see runner.DepQuorum
+	WithJoinCancel option = func(o *Options) { o.joinCancel = true }
 )
 
+// ValidateDep reports all fatal problems of the dependencies as *ValidationError, see Lint for the others
 func (o *Options) ValidateDep() error {
 	if o.dep == nil {
 		return nil
 	}
-	info := o.dep.verify(false)
-	return cond(info != "", errors.New(info), nil)
+	return o.dep.verify().fatal()
+}
+
+// Lint returns the problems of the dependencies that don't break the execution, see ProblemKind.Fatal
+func (o *Options) Lint() []Problem {
+	if o.dep == nil {
+		return nil
+	}
+	if e := o.dep.verify(); e != nil {
+		return filter(e.Problems, func(p Problem) bool { return !p.Kind.Fatal() })
+	}
+	return nil
 }
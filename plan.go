@@ -0,0 +1,76 @@
+package group
+
+import (
+	"errors"
+	"slices"
+)
+
+// Plan is a dry run of the dependency graph, anonymous runners are labelled by func name
+type Plan struct {
+	Order          []string            // topological order
+	Waves          [][]string          // runners in the same wave can run together
+	Nodes          map[string]PlanNode // runner label -> node info
+	MaxConcurrency int                 // max number of runners that can run at the same time
+}
+
+type PlanNode struct {
+	Depth  int // wave index
+	FanIn  int // number of deps
+	FanOut int // number of dependents
+}
+
+// Plan computes the execution plan of the runners with deps without running them
+// the limit should not be less than len(Plan.Order)
+func (o *Options) Plan() (*Plan, error) {
+	if o.dep == nil {
+		return nil, errors.New("dep not enabled")
+	}
+	if err := o.dep.verify().fatal(); err != nil {
+		return nil, err
+	}
+	g := o.Graph()
+	waves, err := g.waves()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plan{Order: slices.Concat(waves...), Waves: waves, Nodes: make(map[string]PlanNode, len(g.g.nodes))}
+	for depth, wave := range waves {
+		for _, node := range wave {
+			p.Nodes[node] = PlanNode{Depth: depth, FanIn: len(g.g.deps[node]), FanOut: len(g.rdeps[node])}
+		}
+	}
+	p.MaxConcurrency = width(p.Order, closure(p.Order, g.g.deps))
+	return p, nil
+}
+
+// returns the size of the largest set of mutually independent nodes (dilworth)
+// order must be topological, anc is the transitive closure of order
+func width(order []string, anc map[string]map[string]token) int {
+	// max bipartite matching ancestor -> descendant (kuhn)
+	match := make(map[string]string, len(order)) // descendant -> ancestor
+	var augment func(node string, seen map[string]token) bool
+	augment = func(node string, seen map[string]token) bool {
+		for _, x := range order {
+			if _, ok := anc[x][node]; !ok {
+				continue
+			}
+			if _, ok := seen[x]; ok {
+				continue
+			}
+			seen[x] = token{}
+			if m, ok := match[x]; !ok || augment(m, seen) {
+				match[x] = node
+				return true
+			}
+		}
+		return false
+	}
+	matched := 0
+	for _, node := range order {
+		if augment(node, make(map[string]token)) {
+			matched++
+		}
+	}
+	return len(order) - matched
+}
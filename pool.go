@@ -0,0 +1,217 @@
+package group
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Pool is a long-lived worker pool the groups can run on, see WithPool
+// a task is handed to an idle worker, or a new worker up to size, or queued up to depth,
+// the tasks beyond are run on new goroutines, so the pool never blocks a group
+// a group nested in a task, run on the task ctx (e.g. MakeGroupRunner), never queues its tasks behind the waiting worker
+type Pool struct {
+	size  int
+	depth int
+	idle  time.Duration
+
+	handoff chan func() // to the idle workers
+	tasks   chan func() // queued tasks
+
+	mu      sync.Mutex
+	workers int
+	closed  bool
+	quit    chan token
+}
+
+// NewPool returns a pool of up to size workers, with up to depth queued tasks,
+// workers idle for the idle duration are reaped (0 for never)
+func NewPool(size, depth int, idle time.Duration) *Pool {
+	return &Pool{
+		size:    max(size, 1),
+		depth:   max(depth, 0),
+		idle:    idle,
+		handoff: make(chan func()),
+		tasks:   make(chan func(), max(depth, 0)),
+		quit:    make(chan token),
+	}
+}
+
+// Workers returns the number of live workers
+func (p *Pool) Workers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.workers
+}
+
+// Close reaps the workers once the queued tasks are done, the later tasks are run on new goroutines
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.quit)
+	}
+}
+
+// submits f, queued only if queue is set, run on a new goroutine instead
+func (p *Pool) submit(f func(), queue bool) {
+	// idle worker
+	select {
+	case p.handoff <- f:
+		return
+	default:
+	}
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		go f()
+		return
+	}
+	if p.workers < p.size {
+		p.workers++
+		p.mu.Unlock()
+		go p.work(f)
+		return
+	}
+	// queued under the lock, so the last worker can't leave it behind
+	if queue {
+		select {
+		case p.tasks <- f:
+			p.mu.Unlock()
+			return
+		default:
+		}
+	}
+	p.mu.Unlock()
+	go f() // overflow
+}
+
+func (p *Pool) work(f func()) {
+	var reap <-chan time.Time
+	var t *time.Timer
+	if p.idle > 0 {
+		t = time.NewTimer(p.idle)
+		defer t.Stop()
+	}
+	for {
+		f()
+		if t != nil {
+			t.Reset(p.idle)
+			reap = t.C
+		}
+		select {
+		case f = <-p.tasks:
+			continue
+		default:
+		}
+		select {
+		case f = <-p.tasks:
+		case f = <-p.handoff:
+		case <-reap:
+			if f = p.leave(); f == nil {
+				return
+			}
+		case <-p.quit:
+			if f = p.leave(); f == nil {
+				return
+			}
+		}
+	}
+}
+
+// the worker leaves unless there's a queued task, returns the task if any
+func (p *Pool) leave() func() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	select {
+	case f := <-p.tasks:
+		return f
+	default:
+	}
+	p.workers--
+	return nil
+}
+
+// errgroup-like group of a run
+type egroup interface {
+	goer
+	SetLimit(n int)
+	Wait() error
+}
+
+type poolKey struct{}
+
+// returns the group of a run on the pool, errgroup if p is nil
+func withContext(ctx context.Context, p *Pool) (egroup, context.Context) {
+	if p == nil {
+		return errgroup.WithContext(ctx)
+	}
+	// run in a task of the same pool, its worker waits for the group
+	nested := ctx.Value(poolKey{}) == p
+	ctx, cancel := context.WithCancelCause(context.WithValue(ctx, poolKey{}, p))
+	return &poolGroup{pool: p, cancel: cancel, nested: nested}, ctx
+}
+
+// errgroup on a pool, the first err cancels the ctx
+type poolGroup struct {
+	pool   *Pool
+	cancel context.CancelCauseFunc
+	nested bool // the tasks are not queued
+	wg     sync.WaitGroup
+	sem    chan token
+
+	errOnce sync.Once
+	err     error
+}
+
+func (g *poolGroup) SetLimit(n int) {
+	g.sem = nil
+	if n >= 0 {
+		g.sem = make(chan token, n)
+	}
+}
+
+func (g *poolGroup) Go(f func() error) {
+	if g.sem != nil {
+		g.sem <- token{}
+	}
+	g.wg.Add(1)
+	g.pool.submit(func() { g.run(f) }, !g.nested)
+}
+
+func (g *poolGroup) TryGo(f func() error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- token{}:
+		default:
+			return false
+		}
+	}
+	g.wg.Add(1)
+	g.pool.submit(func() { g.run(f) }, !g.nested)
+	return true
+}
+
+func (g *poolGroup) run(f func() error) {
+	defer func() {
+		if g.sem != nil {
+			<-g.sem
+		}
+		g.wg.Done()
+	}()
+	if err := f(); err != nil {
+		g.errOnce.Do(func() {
+			g.err = err
+			g.cancel(err)
+		})
+	}
+}
+
+func (g *poolGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel(g.err)
+	return g.err
+}
@@ -0,0 +1,194 @@
+package group
+
+import (
+	"sync"
+	"time"
+)
+
+// EventKind is the kind of a runner event
+type EventKind int
+
+const (
+	Queued   EventKind = iota // submitted to the group
+	Started                   // deps settled, exec started
+	Finished                  // exec succeeded
+	Failed                    // exec failed
+	Skipped                   // not run, e.g. the group failed or a dep failed
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case Queued:
+		return "queued"
+	case Started:
+		return "started"
+	case Finished:
+		return "finished"
+	case Failed:
+		return "failed"
+	case Skipped:
+		return "skipped"
+	}
+	return "unknown"
+}
+
+// Progress is the snapshot of a group run published on the runner events
+type Progress struct {
+	Prefix string
+	// the event that triggered the publish
+	Kind EventKind
+	Name string // runner name, or the func name for anonymous runners and funcs without deps
+	Err  error  // err of Failed
+
+	Total    int // runners of the group
+	Queued   int // submitted, waiting for deps or a slot
+	Running  int
+	Finished int
+	Failed   int
+	Skipped  int
+
+	Elapsed time.Duration
+	ETA     time.Duration // estimated by the throughput so far, 0 if unknown
+	Done    bool          // the last publish of the run
+}
+
+// WithProgress publishes the progress of each run to f, at most once every interval (0 for every event)
+// the first event and the last snapshot are always published, f is called serially and must be fast
+func WithProgress(f func(Progress), every time.Duration) option {
+	return func(o *Options) {
+		o.progress = &progressOpt{f: f, every: every}
+	}
+}
+
+// WithProgressC publishes the progress to c the same as WithProgress, dropped if c is full
+func WithProgressC(c chan<- Progress, every time.Duration) option {
+	return WithProgress(func(p Progress) {
+		select {
+		case c <- p:
+		default:
+		}
+	}, every)
+}
+
+type progressOpt struct {
+	f     func(Progress)
+	every time.Duration
+}
+
+// observer of the runner events of a run
+type observer interface {
+	event(kind EventKind, name string, err error)
+	close()
+}
+
+type observers []observer
+
+// emits the event, name is resolved from label and id only if observed
+func (obs observers) emit(kind EventKind, label string, id func() error, err error) {
+	if len(obs) == 0 {
+		return
+	}
+	name := runnerName(label, id)
+	for _, o := range obs {
+		o.event(kind, name, err)
+	}
+}
+
+func (obs observers) close() {
+	for _, o := range obs {
+		o.close()
+	}
+}
+
+func (o *Options) emit(kind EventKind, label string, id func() error, err error) {
+	if o != nil {
+		o.obs.emit(kind, label, id, err)
+	}
+}
+
+// returns the options of a run with the observers, opts itself if not observed
+// total is the number of the runners of fs
+func (o *Options) observe(fs []func() error) *Options {
+	if o.progress == nil && !o.debug {
+		return o
+	}
+	r := *o
+	r.obs = nil
+	if o.progress != nil {
+		total := len(fs)
+		if o.dep != nil {
+			total = len(o.dep) + len(filter(fs, func(f func() error) bool { return o.dep[fptr(f)] == nil }))
+		}
+		r.obs = append(r.obs, newTracker(o, total))
+	}
+	if o.debug {
+		r.obs = append(r.obs, newDebugRun(o))
+	}
+	return &r
+}
+
+// progress tracker of a run
+type tracker struct {
+	mu     sync.Mutex
+	opt    *progressOpt
+	now    func() time.Time
+	start  time.Time
+	last   time.Time // last publish
+	p      Progress
+	closed bool
+}
+
+func newTracker(o *Options, total int) *tracker {
+	now := time.Now
+	if o.clock != nil {
+		now = o.clock.Now
+	}
+	return &tracker{opt: o.progress, now: now, start: now(), p: Progress{Prefix: o.Prefix, Total: total}}
+}
+
+func (t *tracker) event(kind EventKind, name string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	switch kind {
+	case Queued:
+		t.p.Queued++
+	case Started:
+		t.p.Queued, t.p.Running = t.p.Queued-1, t.p.Running+1
+	case Finished:
+		t.p.Running, t.p.Finished = t.p.Running-1, t.p.Finished+1
+	case Failed:
+		t.p.Running, t.p.Failed = t.p.Running-1, t.p.Failed+1
+	case Skipped:
+		t.p.Queued, t.p.Skipped = t.p.Queued-1, t.p.Skipped+1
+	}
+	t.p.Kind, t.p.Name, t.p.Err = kind, name, err
+	now := t.now()
+	if settled := t.p.Finished + t.p.Failed + t.p.Skipped; settled == t.p.Total {
+		t.publish(now, true)
+		return
+	}
+	if t.last.IsZero() || now.Sub(t.last) >= t.opt.every {
+		t.publish(now, false)
+	}
+}
+
+// publishes the last snapshot if not yet, the later events are dropped
+func (t *tracker) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.closed {
+		t.publish(t.now(), true)
+	}
+}
+
+func (t *tracker) publish(now time.Time, done bool) {
+	t.p.Elapsed, t.p.ETA, t.p.Done = now.Sub(t.start), 0, done
+	if settled := t.p.Finished + t.p.Failed + t.p.Skipped; settled > 0 && !done {
+		t.p.ETA = t.p.Elapsed * time.Duration(t.p.Total-settled) / time.Duration(settled)
+	}
+	t.last, t.closed = now, done
+	t.opt.f(t.p)
+}
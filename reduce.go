@@ -0,0 +1,130 @@
+package group
+
+import (
+	"slices"
+)
+
+// Edge is a dependency edge, Node depends on Dep
+type Edge struct {
+	Node, Dep string
+}
+
+// Redundant returns the edges implied by other paths (transitive reduction)
+// e.g. "c" -> "a" is redundant if "c" -> "b" -> "a", nil if the graph is broken
+func (g *Graph) Redundant() []Edge {
+	waves, err := g.waves()
+	if err != nil {
+		return nil
+	}
+	anc := closure(slices.Concat(waves...), g.g.deps)
+	var edges []Edge
+	for _, node := range g.g.nodes {
+		for _, dep := range redundant(g.g.deps[node], anc) {
+			edges = append(edges, Edge{Node: node, Dep: dep})
+		}
+	}
+	return edges
+}
+
+// Reduce returns the transitively reduced graph, the graph is returned as is if broken
+func (g *Graph) Reduce() *Graph {
+	edges := g.Redundant()
+	if len(edges) == 0 {
+		return g
+	}
+	r := &Graph{
+		g:     &depGraph{nodes: g.g.nodes, deps: make(map[string][]string, len(g.g.deps)), anon: g.g.anon, dup: g.g.dup},
+		rdeps: make(map[string][]string, len(g.rdeps)),
+		tol:   g.tol,
+	}
+	for _, node := range g.g.nodes {
+		r.g.deps[node] = filter(g.g.deps[node], func(dep string) bool { return !slices.Contains(edges, Edge{Node: node, Dep: dep}) })
+		for _, dep := range r.g.deps[node] {
+			r.rdeps[dep] = append(r.rdeps[dep], node)
+		}
+	}
+	return r
+}
+
+// returns a copy of depMap without redundant waits, depMap is returned as is if broken
+func (d depMap) reduce() depMap {
+	g := (&Options{dep: d}).Graph()
+	waves, err := g.waves()
+	if err != nil {
+		return d
+	}
+	named := make(map[string]*fdep, len(d))
+	for _, fd := range d {
+		if fd.deps[0] != "" {
+			named[fd.deps[0]] = fd
+		}
+	}
+	// the failure of the dep fails the runner, unless tolerated or joined
+	strict := func(fd *fdep) []string {
+		return filter(fd.deps[1:], func(dep string) bool {
+			return !slices.Contains(fd.tol, dep) && !joined(fd, dep) && (named[dep] == nil || !named[dep].tolerant)
+		})
+	}
+	// a wait is implied only by the strict paths, a failure may not pass a tolerated edge
+	deps := make(map[string][]string, len(named))
+	for name, fd := range named {
+		deps[name] = strict(fd)
+	}
+	anc := closure(slices.Concat(waves...), deps)
+	r := make(depMap, len(d))
+	for p, fd := range d {
+		if rd := redundant(strict(fd), anc); len(rd) > 0 {
+			// duplicate deps are dropped as well
+			var deps []string
+			for _, dep := range fd.deps[1:] {
+				if !slices.Contains(rd, dep) && !slices.Contains(deps, dep) {
+					deps = append(deps, dep)
+				}
+			}
+			x := *fd
+			x.deps = append([]string{fd.deps[0]}, deps...)
+			r[p] = &x
+			continue
+		}
+		r[p] = fd
+	}
+	return r
+}
+
+// returns the deps reachable from the other deps
+func redundant(deps []string, anc map[string]map[string]token) []string {
+	var r []string
+	for _, dep := range deps {
+		for _, x := range deps {
+			if _, ok := anc[x][dep]; ok && x != dep {
+				r = append(r, dep)
+				break
+			}
+		}
+	}
+	return r
+}
+
+// transitive closure, node -> ancestors
+// order must be topological
+func closure(order []string, deps map[string][]string) map[string]map[string]token {
+	anc := make(map[string]map[string]token, len(order))
+	for _, node := range order {
+		anc[node] = make(map[string]token)
+		for _, dep := range deps[node] {
+			anc[node][dep] = token{}
+			for a := range anc[dep] {
+				anc[node][a] = token{}
+			}
+		}
+	}
+	return anc
+}
+
+// returns the deps to run without the absent soft deps, reduced with WithReduce
+func (o *Options) deps() depMap {
+	if o.reduce {
+		return o.dep.present().reduce()
+	}
+	return o.dep.present()
+}
@@ -2,21 +2,46 @@ package group
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"runtime"
 )
 
 const bufSize int = 64 << 10
 
+// PanicError is the err of a panic recovered by SafeRun
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("runtime panic: %v", e.Value)
+}
+
 func RecoverContext(ctx context.Context) {
 	if x := recover(); x != nil {
-		buf := make([]byte, bufSize)
-		buf = buf[:runtime.Stack(buf, false)]
-		slog.ErrorContext(ctx, "runtime panic: %v\n%v", x, string(buf))
+		logPanic(ctx, x, stack())
 	}
 }
 
-func SafeRun(ctx context.Context, f func() error) error {
-	defer RecoverContext(ctx)
+// SafeRun runs f and recovers the panic as *PanicError
+func SafeRun(ctx context.Context, f func() error) (err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			buf := stack()
+			logPanic(ctx, x, buf)
+			err = &PanicError{Value: x, Stack: buf}
+		}
+	}()
 	return f()
 }
+
+func stack() []byte {
+	buf := make([]byte, bufSize)
+	return buf[:runtime.Stack(buf, false)]
+}
+
+func logPanic(ctx context.Context, x any, buf []byte) {
+	slog.ErrorContext(ctx, fmt.Sprintf("runtime panic: %v", x), slog.String("stack", string(buf)))
+}
@@ -0,0 +1,95 @@
+package group
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CompensationError is the err of a failed group with compensations, see runner.Compensate
+type CompensationError struct {
+	Err        error            // err of the group
+	RolledBack []string         // runners compensated, in order
+	Failed     map[string]error // runners whose compensation failed
+}
+
+func (e *CompensationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v (rolled back: %s)", e.Err, strings.Join(e.RolledBack, ", "))
+	for _, name := range slices.Sorted(maps.Keys(e.Failed)) {
+		fmt.Fprintf(&b, "; compensation of %s failed: %v", name, e.Failed[name])
+	}
+	return b.String()
+}
+
+func (e *CompensationError) Unwrap() error { return e.Err }
+
+// runs the compensations of the succeeded runners if the group failed with err, dependents first
+// returns err as is if no compensation is run
+func compensate(ctx context.Context, opts *Options, d depMap, succeeded []*fdep, err error) error {
+	if err == nil {
+		return nil
+	}
+	succeeded = filter(succeeded, func(fd *fdep) bool { return fd.comp != nil })
+	if len(succeeded) == 0 {
+		return err
+	}
+	depth := d.depths()
+	slices.SortFunc(succeeded, func(a, b *fdep) int {
+		return cmp.Or(cmp.Compare(depth[b], depth[a]), strings.Compare(runnerName(a.deps[0], a.f), runnerName(b.deps[0], b.f)))
+	})
+	ce := &CompensationError{Err: err}
+	for _, fd := range succeeded {
+		name := runnerName(fd.deps[0], fd.f)
+		cctx, cancel := finalContext(ctx, opts)
+		start := time.Now()
+		x := SafeRun(cctx, func() error { return fd.comp(cctx) })
+		funcMonitor(cctx, "Compensate", opts.Prefix, name, fd.f, start, opts.WithLog, nil, x)
+		cancel()
+		if x != nil {
+			if ce.Failed == nil {
+				ce.Failed = make(map[string]error)
+			}
+			ce.Failed[name] = x
+			continue
+		}
+		ce.RolledBack = append(ce.RolledBack, name)
+	}
+	return ce
+}
+
+// returns the depth of the runners, the longest dep path to a root, cycles are cut
+func (d depMap) depths() map[*fdep]int {
+	named := make(map[string]*fdep, len(d))
+	for _, fd := range d {
+		if fd.deps[0] != "" {
+			named[fd.deps[0]] = fd
+		}
+	}
+	depth := make(map[*fdep]int, len(d))
+	visiting := make(map[*fdep]bool)
+	var walk func(fd *fdep) int
+	walk = func(fd *fdep) int {
+		if x, ok := depth[fd]; ok || visiting[fd] {
+			return x
+		}
+		visiting[fd] = true
+		var x int
+		for _, dep := range fd.deps[1:] {
+			if dfd := named[dep]; dfd != nil {
+				x = max(x, walk(dfd)+1)
+			}
+		}
+		visiting[fd] = false
+		depth[fd] = x
+		return x
+	}
+	for _, fd := range d {
+		walk(fd)
+	}
+	return depth
+}
@@ -0,0 +1,245 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Scheduler picks the next runner to run by index from the ready ones, e.g. grouptest.Seeded
+// with a scheduler, runners are run one at a time on the caller goroutine in the picked order
+// ready is in the declaration order, labelled as the runner name, or the func name for anonymous runners and funcs without deps
+type Scheduler interface {
+	Next(ready []string) int
+}
+
+// picks the first ready runner, i.e. the stable topological order of the declaration
+type serial struct{}
+
+func (serial) Next([]string) int { return 0 }
+
+// runner scheduled serially
+type snode struct {
+	fd    *fdep // nil for funcs without deps
+	f     func() error
+	label string
+}
+
+// runs fs one at a time, with the same fast-fail and tolerance semantics as the concurrent engine
+// ctx is the caller ctx, tctx is the group ctx with timeout
+func serialGo(ctx, tctx context.Context, method string, opts *Options, fs ...func() error) (err error) {
+	d := opts.deps()
+	// runners in the declaration order
+	var nodes []snode
+	seen := make(map[uintptr]token, len(d))
+	for _, f := range fs {
+		fd := d[fptr(f)]
+		if fd == nil {
+			nodes = append(nodes, snode{f: f, label: funcName(f)})
+			continue
+		}
+		if _, ok := seen[fptr(f)]; !ok {
+			seen[fptr(f)] = token{}
+			nodes = append(nodes, snode{fd: fd, f: fd.f, label: runnerName(fd.deps[0], fd.f)})
+		}
+	}
+	// runners with deps are run even if not in fs, the same as the concurrent engine
+	var rest []snode
+	for p, fd := range d {
+		if _, ok := seen[p]; !ok {
+			rest = append(rest, snode{fd: fd, f: fd.f, label: runnerName(fd.deps[0], fd.f)})
+		}
+	}
+	slices.SortFunc(rest, func(a, b snode) int { return strings.Compare(a.label, b.label) })
+	nodes = append(nodes, rest...)
+
+	named := make(map[string]token, len(d))
+	for _, fd := range d {
+		if fd.deps[0] != "" {
+			named[fd.deps[0]] = token{}
+		}
+	}
+	for _, n := range nodes {
+		opts.emit(Queued, n.label, n.f, nil)
+	}
+	tol := make(map[string]token) // tolerant & tolerance propagated in this run
+	for _, fd := range d {
+		if fd.tolerant && fd.deps[0] != "" {
+			tol[fd.deps[0]] = token{}
+		}
+	}
+	down := make(map[string]error) // failed or skipped
+	settled := make(map[string]token, len(d))
+	var failed bool // fast-failed, the same as the group ctx cancelled
+	var stop error  // ctx cancelled or group timeout
+	var finals []error
+	var succeeded []*fdep            // to be compensated if the group fails
+	passed := make(map[string]token) // succeeded
+	only := d.joinOnly()             // failures decided by the joins
+	// counts the settled members of the join, the missing ones fail in exec
+	count := func(j join) (ok, fail int, errs []error) {
+		for _, dep := range j.deps {
+			_, exist := named[dep]
+			if _, x := passed[dep]; x {
+				ok++
+			} else if _, x := settled[dep]; x || !exist {
+				fail, errs = fail+1, append(errs, down[dep])
+			}
+		}
+		return ok, fail, errs
+	}
+	decided := func(j join) bool {
+		ok, fail, _ := count(j)
+		return ok >= j.k || fail > len(j.deps)-j.k || !j.valid()
+	}
+	var rdeps map[string][]*fdep // dependents, to cancel the joined deps no longer needed
+	if opts.joinCancel && only != nil {
+		rdeps = make(map[string][]*fdep)
+		for _, fd := range d {
+			for _, dep := range fd.deps[1:] {
+				rdeps[dep] = append(rdeps[dep], fd)
+			}
+		}
+	}
+	for len(nodes) > 0 {
+		// ready runners
+		var ready []int
+		for i, n := range nodes {
+			if n.fd == nil || all(n.fd.deps[1:], func(dep string) bool {
+				_, ok := settled[dep]
+				_, exist := named[dep]
+				return ok || !exist || joined(n.fd, dep) // missing dep fails in exec
+			}) && all(n.fd.joins, decided) {
+				ready = append(ready, i)
+			}
+		}
+		if len(ready) == 0 {
+			return errors.New("dependency cycle detected, no runner is ready")
+		}
+		labels := make([]string, len(ready))
+		for i, x := range ready {
+			labels[i] = nodes[x].label
+		}
+		i := ready[min(max(opts.sched.Next(labels), 0), len(ready)-1)]
+		n := nodes[i]
+		nodes = slices.Delete(nodes, i, i+1)
+		if n.fd != nil && n.fd.deps[0] != "" {
+			settled[n.fd.deps[0]] = token{}
+		}
+
+		// ctx check before exec, only the finalizers run on
+		if stop == nil && ctx.Err() != nil {
+			stop = ctx.Err()
+		} else if stop == nil && tctx.Err() != nil {
+			if opts.WithLog {
+				timeoutMonitor(tctx, method, opts.Prefix, opts.Timeout)
+			}
+			stop = errors.New("group timeout")
+		}
+		if n.fd != nil && n.fd.final {
+			fctx, cancel := finalContext(tctx, opts)
+			if x := execSerial(fctx, method, opts, n, named, nil); x != nil {
+				down[n.label], finals = x, append(finals, x)
+			} else {
+				succeeded = append(succeeded, n.fd)
+			}
+			cancel()
+			continue
+		}
+		if stop != nil {
+			down[n.label] = stop
+			opts.emit(Skipped, n.label, n.f, nil)
+			continue
+		}
+		_, soft := only[n.label]
+		if soft && rdeps != nil && all(rdeps[n.label], func(fd *fdep) bool {
+			return all(fd.joins, func(j join) bool { return decided(j) || !slices.Contains(j.deps, n.label) })
+		}) {
+			down[n.label] = errUnneeded
+			opts.emit(Skipped, n.label, n.f, errUnneeded)
+			continue
+		}
+
+		var depErr error // record dep err
+		if failed {
+			// early-stage err check & tolerance check
+			if n.fd == nil || len(n.fd.deps) == 1 || !tolerates(n.fd, func(dep string) (bool, bool) {
+				if joined(n.fd, dep) {
+					return false, false
+				}
+				_, t := tol[dep]
+				_, f := down[dep]
+				return t, f
+			}) {
+				down[n.label] = context.Canceled
+				opts.emit(Skipped, n.label, n.f, nil)
+				continue
+			}
+			// propagate tolerance & record err
+			if depErr = context.Canceled; n.fd.deps[0] != "" {
+				tol[n.fd.deps[0]] = token{}
+			}
+		}
+		var x error // quorum err
+		if n.fd != nil {
+			for _, j := range n.fd.joins {
+				if ok, _, errs := count(j); x == nil {
+					x = j.err(ok, errs)
+				}
+			}
+		}
+		if x != nil {
+			opts.emit(Skipped, n.label, n.f, x)
+		} else if x = execSerial(tctx, method, opts, n, named, depErr); n.fd != nil && x == depErr {
+			succeeded = append(succeeded, n.fd) // depErr is returned as is on success
+			passed[n.label] = token{}
+		}
+		if x != nil {
+			down[n.label] = x
+			if soft {
+				continue // decided by the joins
+			}
+			failed, err = true, cond(err != nil, err, x)
+		}
+	}
+	return compensate(tctx, opts, d, succeeded, joinFinal(cond(stop != nil, stop, err), finals))
+}
+
+func execSerial(tctx context.Context, method string, opts *Options, n snode, named map[string]token, depErr error) (err error) {
+	if n.fd != nil {
+		for _, dep := range n.fd.deps[1:] {
+			if _, ok := named[dep]; !ok {
+				opts.emit(Skipped, n.label, n.f, nil)
+				return fmt.Errorf("missing dep signal for %s", dep)
+			}
+		}
+	}
+	if opts.WithLog || opts.errs() != nil {
+		defer func(start time.Time) {
+			funcMonitor(tctx, method, opts.Prefix, n.label, n.f, start, opts.WithLog, opts.errs(), err)
+		}(time.Now())
+	}
+	f := opts.bind(n.f, tctx)
+	if n.fd != nil {
+		f = bind(opts.dep, n.fd, tctx)
+	}
+	opts.emit(Started, n.label, n.f, nil)
+	err = SafeRun(tctx, intercept(tctx, opts, n.label, n.f, f))
+	opts.emit(cond(err != nil, Failed, Finished), n.label, n.f, err)
+	if err != nil {
+		return cond(depErr != nil, fmt.Errorf("%v -> %w", depErr, err), err)
+	}
+	return depErr
+}
+
+func all[T any](s []T, f func(T) bool) bool {
+	for _, v := range s {
+		if !f(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+package group
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrorSink receives the errors of the failed runners, see WithErrorSink
+// Put is called on the runner goroutines, it must be safe for concurrent use and should not block
+type ErrorSink interface {
+	Put(err error)
+}
+
+// WithErrorSink puts the errors of the failed runners to s, ErrC is not used if a sink is set
+func WithErrorSink(s ErrorSink) option { return func(o *Options) { o.sink = s } }
+
+// ErrC of WithErrorCollector, blocks the runner until received
+type chanSink chan error
+
+func (c chanSink) Put(err error) { c <- err }
+
+// returns the sink of the errors, nil if not collected
+func (o *Options) errs() ErrorSink {
+	if o.sink != nil {
+		return o.sink
+	}
+	if o.ErrC != nil {
+		return chanSink(o.ErrC)
+	}
+	return nil
+}
+
+// SinkFunc calls the func with the errors, it must be safe for concurrent use
+type SinkFunc func(err error)
+
+func (f SinkFunc) Put(err error) { f(err) }
+
+// DropSink buffers up to size errors, the errors beyond are dropped and counted
+type DropSink struct {
+	c       chan error
+	dropped atomic.Int64
+}
+
+func NewDropSink(size int) *DropSink {
+	return &DropSink{c: make(chan error, max(size, 0))}
+}
+
+func (s *DropSink) Put(err error) {
+	select {
+	case s.c <- err:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// C returns the buffered errors
+func (s *DropSink) C() <-chan error { return s.c }
+
+// Dropped returns the number of dropped errors
+func (s *DropSink) Dropped() int64 { return s.dropped.Load() }
+
+// TimeoutSink sends the errors to c, an error not received within the timeout is dropped and counted
+type TimeoutSink struct {
+	c       chan<- error
+	timeout time.Duration
+	dropped atomic.Int64
+}
+
+func NewTimeoutSink(c chan<- error, timeout time.Duration) *TimeoutSink {
+	return &TimeoutSink{c: c, timeout: timeout}
+}
+
+func (s *TimeoutSink) Put(err error) {
+	select {
+	case s.c <- err:
+		return
+	default:
+	}
+	t := time.NewTimer(s.timeout)
+	defer t.Stop()
+	select {
+	case s.c <- err:
+	case <-t.C:
+		s.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of dropped errors
+func (s *TimeoutSink) Dropped() int64 { return s.dropped.Load() }
+
+// Collector collects all errors in the order they are put, read them after Go returns
+type Collector struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (c *Collector) Put(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errs = append(c.errs, err)
+}
+
+// Errors returns the collected errors in order
+func (c *Collector) Errors() []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]error(nil), c.errs...)
+}
+
+// Err returns the collected errors joined, nil if none
+func (c *Collector) Err() error {
+	return errors.Join(c.Errors()...)
+}
@@ -0,0 +1,168 @@
+package group
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Registry maps names to funcs for the graphs loaded from spec
+type Registry struct {
+	mu sync.RWMutex
+	fs map[string]func(context.Context) error
+}
+
+func NewRegistry() *Registry {
+	return &Registry{fs: make(map[string]func(context.Context) error)}
+}
+
+// Register registers f under name, f has no ctx, so the node timeout doesn't apply to it, use RegisterContext instead
+func (r *Registry) Register(name string, f func() error) *Registry {
+	return r.RegisterContext(name, func(context.Context) error { return f() })
+}
+
+// RegisterContext registers the context-aware f under name
+// f gets the group ctx, which is cancelled on fast-fail and timeout
+func (r *Registry) RegisterContext(name string, f func(context.Context) error) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fs[name] = f
+	return r
+}
+
+func (r *Registry) lookup(name string) func(context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.fs[name]
+}
+
+// Spec is the declarative definition of a dependency graph, e.g.
+//
+//	{
+//		"prefix": "order", "limit": 4, "timeout": "3s",
+//		"nodes": [
+//			{"name": "user", "timeout": "1s"},
+//			{"name": "cache", "tolerant": true},
+//			{"name": "order", "func": "loadOrder", "deps": ["user"], "tolerate": ["cache"]}
+//		]
+//	}
+type Spec struct {
+	Prefix  string     `json:"prefix,omitempty"`
+	Limit   int        `json:"limit,omitempty"`
+	Timeout Duration   `json:"timeout,omitempty"`
+	WithLog bool       `json:"log,omitempty"`
+	Nodes   []NodeSpec `json:"nodes"`
+}
+
+type NodeSpec struct {
+	Name     string     `json:"name"`
+	Func     string     `json:"func,omitempty"` // registry name, defaults to Name
+	Deps     []string   `json:"deps,omitempty"`
+	Tolerant bool       `json:"tolerant,omitempty"`
+	Tolerate []string   `json:"tolerate,omitempty"` // deps whose failure is tolerated by the node
+	Optional []string   `json:"optional,omitempty"` // soft deps, may be absent, see runner.SoftDep
+	Joins    []JoinSpec `json:"joins,omitempty"`    // k-of-n joins, see runner.DepQuorum
+	Finally  bool       `json:"finally,omitempty"`  // finalizer, see runner.Finally
+	Timeout  Duration   `json:"timeout,omitempty"`  // node timeout, for the context-aware funcs only
+}
+
+// JoinSpec is a join of the deps, the node waits for K of them to succeed
+type JoinSpec struct {
+	K    int      `json:"k"`
+	Deps []string `json:"deps"`
+}
+
+// Duration is a time.Duration encoded as string ("1.5s") or number of nanoseconds in json
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch x := v.(type) {
+	case float64:
+		*d = Duration(x)
+	case string:
+		t, err := time.ParseDuration(x)
+		if err != nil {
+			return err
+		}
+		*d = Duration(t)
+	default:
+		return fmt.Errorf("invalid duration %s", b)
+	}
+	return nil
+}
+
+// LoadSpec reads a json spec, unknown fields are rejected
+func LoadSpec(r io.Reader) (*Spec, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var s Spec
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+	return &s, nil
+}
+
+// Build builds the options and runners of the spec, equivalent to the Name/Dep/DepTolerant/SoftDep/DepQuorum/Tolerant/Finally chain
+// the dependencies are verified, the funcs are looked up from reg
+func (s *Spec) Build(reg *Registry) (*Options, []func() error, error) {
+	var opts = Opts(WithDep, WithPrefix(s.Prefix), WithLimit(s.Limit), WithTimeout(time.Duration(s.Timeout)))
+	opts.WithLog = s.WithLog
+	fs := make([]func() error, 0, len(s.Nodes))
+	for _, n := range s.Nodes {
+		if n.Name == "" {
+			return nil, nil, errors.New("invalid spec: node name is required")
+		}
+		fn := cond(n.Func != "", n.Func, n.Name)
+		fc := reg.lookup(fn)
+		if fc == nil {
+			return nil, nil, fmt.Errorf("invalid spec: func %q of node %q is not registered", fn, n.Name)
+		}
+		if n.Timeout > 0 {
+			fc = withTimeout(fc, time.Duration(n.Timeout))
+		}
+		r := MakeContextRunner(opts, fc).Name(opts, n.Name).Dep(opts, n.Deps...).DepTolerant(opts, n.Tolerate...).SoftDep(opts, n.Optional...)
+		if n.Tolerant {
+			r.Tolerant(opts)
+		}
+		if n.Finally {
+			r.Finally(opts)
+		}
+		for _, j := range n.Joins {
+			r.DepQuorum(opts, j.K, j.Deps...)
+		}
+		fs = append(fs, r)
+	}
+	if err := opts.dep.verify().fatal(); err != nil {
+		return nil, nil, fmt.Errorf("invalid spec: %w", err)
+	}
+	return opts, fs, nil
+}
+
+// Go builds the spec and runs it with Go
+func (s *Spec) Go(ctx context.Context, reg *Registry) error {
+	opts, fs, err := s.Build(reg)
+	if err != nil {
+		return err
+	}
+	return Go(ctx, opts, fs...)
+}
+
+func withTimeout(f func(context.Context) error, t time.Duration) func(context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, t)
+		defer cancel()
+		return f(ctx)
+	}
+}
@@ -0,0 +1,85 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Store is the per-run typed store shared by the context runners, see MakeContextRunner
+// runners put values under their own name and get values of the runners they depend on
+type Store struct {
+	mu sync.RWMutex
+	vs map[string]any
+}
+
+type storeKey struct{}
+type scopeKey struct{}
+
+// runner scope in the store
+type scope struct {
+	name string
+	deps []string
+}
+
+// returns ctx carrying a new store
+func withStore(ctx context.Context) context.Context {
+	return context.WithValue(ctx, storeKey{}, &Store{vs: make(map[string]any)})
+}
+
+// returns ctx carrying the scope of the runner
+func withScope(ctx context.Context, fd *fdep) context.Context {
+	if ctx.Value(storeKey{}) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, scopeKey{}, &scope{name: fd.deps[0], deps: fd.deps[1:]})
+}
+
+func storeFrom(ctx context.Context) (*Store, *scope, error) {
+	s, _ := ctx.Value(storeKey{}).(*Store)
+	if s == nil {
+		return nil, nil, errors.New("store not found in ctx")
+	}
+	sc, _ := ctx.Value(scopeKey{}).(*scope)
+	if sc == nil || sc.name == "" {
+		return nil, nil, errors.New("store is only accessible by named runners")
+	}
+	return s, sc, nil
+}
+
+// Put puts v under the name of the runner of ctx, overwriting the previous value
+func Put[T any](ctx context.Context, v T) error {
+	s, sc, err := storeFrom(ctx)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vs[sc.name] = v
+	return nil
+}
+
+// Get gets the value put by the runner name, which must be declared as a dep of the runner of ctx
+func Get[T any](ctx context.Context, name string) (T, error) {
+	var zero T
+	s, sc, err := storeFrom(ctx)
+	if err != nil {
+		return zero, err
+	}
+	if name != sc.name && !slices.Contains(sc.deps, name) {
+		return zero, fmt.Errorf("undeclared dep %s of %s", name, sc.name)
+	}
+	s.mu.RLock()
+	x, ok := s.vs[name]
+	s.mu.RUnlock()
+	if !ok {
+		return zero, fmt.Errorf("no value put by %s", name)
+	}
+	v, ok := x.(T)
+	if !ok {
+		return zero, fmt.Errorf("value put by %s is %T, not %T", name, x, zero)
+	}
+	return v, nil
+}
@@ -0,0 +1,105 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"iter"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+type stress struct {
+	seed     uint64
+	runs     int
+	maxDelay time.Duration
+}
+
+// WithStress runs the group runs times, each run shuffles the start order and injects random delays
+// (up to maxDelay) before and after each runner, to expose the missing deps between runners sharing state
+// seed 0 picks a random seed, the seed is reported by *StressError and can be replayed by the same option
+func WithStress(seed uint64, runs int, maxDelay time.Duration) option {
+	return func(o *Options) { o.stress = &stress{seed: seed, runs: max(runs, 1), maxDelay: maxDelay} }
+}
+
+// StressError is the err of the first failed run in stress mode
+type StressError struct {
+	Seed uint64
+	Run  int
+	Err  error
+}
+
+func (e *StressError) Error() string {
+	return fmt.Sprintf("stress seed %d run %d: %v", e.Seed, e.Run, e.Err)
+}
+
+func (e *StressError) Unwrap() error {
+	return e.Err
+}
+
+// runs the group in stress mode with Go (or TryGo if try), stops at the first failed run
+func stressGo(ctx context.Context, opts *Options, try bool, fs ...func() error) (ok bool, err error) {
+	s := *opts.stress
+	if s.seed == 0 {
+		s.seed = rand.Uint64()
+	}
+	if opts.WithLog {
+		slog.InfoContext(ctx, fmt.Sprintf("[Group %s | Stress] group %s", cond(try, "TryGo", "Go"), cond(opts.Prefix != "", opts.Prefix, "anonymous")),
+			slog.Uint64("seed", s.seed), slog.Int("runs", s.runs))
+	}
+	for run := range s.runs {
+		rng := rand.New(rand.NewPCG(s.seed, uint64(run)))
+		o := *opts
+		o.stress, o.shuffle = nil, rng
+		o.icpt = append([]Interceptor{s.delay(run)}, opts.icpt...)
+		xs := slices.Clone(fs)
+		rng.Shuffle(len(xs), func(i, j int) { xs[i], xs[j] = xs[j], xs[i] })
+		if try {
+			ok, err = TryGo(ctx, &o, xs...)
+		} else {
+			ok, err = true, Go(ctx, &o, xs...)
+		}
+		if err != nil {
+			return ok, &StressError{Seed: s.seed, Run: run, Err: err}
+		}
+	}
+	return ok, nil
+}
+
+// returns the interceptor injecting delays, the delays of each runner only depend on seed, run and name
+func (s stress) delay(run int) Interceptor {
+	return func(ctx context.Context, name string, f func() error) error {
+		if s.maxDelay <= 0 {
+			return f()
+		}
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(name))
+		rng := rand.New(rand.NewPCG(s.seed^uint64(run), h.Sum64()))
+		before, after := time.Duration(rng.Int64N(int64(s.maxDelay))), time.Duration(rng.Int64N(int64(s.maxDelay)))
+		sleep(ctx, before)
+		defer sleep(ctx, after)
+		return f()
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
+
+// returns the runners in the start order, shuffled if rng is set
+func (d depMap) keys(rng *rand.Rand) iter.Seq[uintptr] {
+	if rng == nil {
+		return maps.Keys(d)
+	}
+	keys := slices.Sorted(maps.Keys(d))
+	rng.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+	return slices.Values(keys)
+}
@@ -0,0 +1,54 @@
+package group
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+)
+
+// SubgroupError is the error of a subgroup runner, see MakeGroupRunner
+type SubgroupError struct {
+	Prefix string  // prefix of the subgroup
+	Err    error   // err of the subgroup
+	Errs   []error // errors of the failed runners of the subgroup, in order
+}
+
+func (e *SubgroupError) Error() string { return fmt.Sprintf("subgroup %s: %v", e.Prefix, e.Err) }
+
+func (e *SubgroupError) Unwrap() error { return e.Err }
+
+// MakeGroupRunner makes a runner of opts that runs fs as a subgroup with its own options, i.e. limit, timeout and deps
+// the subgroup is run on the group ctx, so it is cancelled with the group, the prefix is "<group>/<subgroup>",
+// the subgroup prefix defaults to the runner name, the subgroup err is returned as *SubgroupError
+func MakeGroupRunner(opts *Options, sub *Options, fs ...func() error) runner {
+	var r runner
+	r = MakeContextRunner(opts, func(ctx context.Context) error {
+		var c Options
+		if sub != nil {
+			c = *sub // per-run copy
+		}
+		c.Prefix = opts.Prefix + "/" + cmp.Or(c.Prefix, subName(opts, r))
+		// collect the runner errors, forwarded to the subgroup sink if any
+		var errs Collector
+		next := c.errs()
+		c.sink = SinkFunc(func(err error) {
+			errs.Put(err)
+			if next != nil {
+				next.Put(err)
+			}
+		})
+		if err := Go(ctx, &c, fs...); err != nil {
+			return &SubgroupError{Prefix: c.Prefix, Err: err, Errs: errs.Errors()}
+		}
+		return nil
+	})
+	return r
+}
+
+// returns the runner name of r, "subgroup" if anonymous
+func subName(opts *Options, r runner) string {
+	if fd := opts.dep[fptr(r)]; fd != nil && fd.deps[0] != "" {
+		return fd.deps[0]
+	}
+	return "subgroup"
+}
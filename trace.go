@@ -0,0 +1,37 @@
+package group
+
+import (
+	"context"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// runs f with the pprof labels group=prefix and runner=name, in a trace region of the runner name
+func traceRunner(ctx context.Context, prefix, name string, f func() error) (err error) {
+	pprof.Do(ctx, pprof.Labels("group", prefix, "runner", name), func(ctx context.Context) {
+		trace.WithRegion(ctx, name, func() { err = f() })
+	})
+	return err
+}
+
+// starts the trace task of a run if traced
+func (o *Options) task(ctx context.Context) (context.Context, func()) {
+	if !o.trace {
+		return ctx, nop
+	}
+	ctx, task := trace.NewTask(ctx, "group "+o.Prefix)
+	return ctx, task.End
+}
+
+// starts the dep wait of the runner as a trace task if traced, ended once its deps are settled
+// a task, unlike a region, can be ended by the goroutine settling the last dep
+func (o *Options) wait(ctx context.Context, name string) func() {
+	if !o.trace {
+		return nop
+	}
+	ctx, task := trace.NewTask(ctx, "group.wait")
+	trace.Log(ctx, "runner", name)
+	return task.End
+}
+
+func nop() {}
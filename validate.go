@@ -0,0 +1,260 @@
+package group
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type ProblemKind int
+
+const (
+	DuplicateName     ProblemKind = iota // multiple runners with the same name
+	MissingDep                           // dep is not a named runner
+	Cycle                                // dependency cycle
+	SelfDep                              // runner depends on itself
+	AnonymousTolerant                    // Tolerant on anonymous runner has no effect
+	Unreachable                          // runner can never run due to a broken upstream
+	Unused                               // named runner without deps and dependents
+	InvalidQuorum                        // k of DepQuorum is out of 1..len(deps)
+)
+
+// Fatal reports whether the problem breaks the execution
+func (k ProblemKind) Fatal() bool {
+	return k != AnonymousTolerant && k != Unused
+}
+
+type Problem struct {
+	Kind ProblemKind
+	Node string   // runner label, anonymous runners are labelled by func name
+	Path []string // missing dep for MissingDep, cycle for Cycle, broken upstreams for Unreachable, joined deps for InvalidQuorum
+	K    int      // k of the join for InvalidQuorum
+}
+
+func (p Problem) String() string {
+	switch p.Kind {
+	case DuplicateName:
+		return fmt.Sprintf("duplicate dependency source %q", p.Node)
+	case MissingDep:
+		return fmt.Sprintf("missing dependency %q -> %q", p.Node, p.Path[0])
+	case Cycle:
+		return fmt.Sprintf("dependency cycle detected: %s", quoteJoin(p.Path, " -> "))
+	case SelfDep:
+		return fmt.Sprintf("self dependency %q", p.Node)
+	case AnonymousTolerant:
+		return fmt.Sprintf("tolerant anonymous runner %s", p.Node)
+	case Unreachable:
+		return fmt.Sprintf("unreachable runner %q, broken upstream %s", p.Node, quoteJoin(p.Path, ", "))
+	case Unused:
+		return fmt.Sprintf("unused runner %q", p.Node)
+	case InvalidQuorum:
+		return fmt.Sprintf("invalid quorum %d of %s on %q", p.K, quoteJoin(p.Path, ", "), p.Node)
+	}
+	return fmt.Sprintf("unknown problem %d on %q", p.Kind, p.Node)
+}
+
+// ValidationError lists all problems of the dependencies
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0].String()
+	}
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.String()
+	}
+	return fmt.Sprintf("%d dependency problems: %s", len(e.Problems), strings.Join(msgs, "; "))
+}
+
+// returns the fatal problems only
+func (e *ValidationError) fatal() error {
+	if e == nil {
+		return nil
+	}
+	if ps := filter(e.Problems, func(p Problem) bool { return p.Kind.Fatal() }); len(ps) > 0 {
+		return &ValidationError{Problems: ps}
+	}
+	return nil
+}
+
+func quoteJoin(s []string, sep string) string {
+	q := make([]string, len(s))
+	for i, x := range s {
+		q[i] = fmt.Sprintf("%q", x)
+	}
+	return strings.Join(q, sep)
+}
+
+// checks duplication, existence, cycles, tolerance, reachability and joins
+func (d depMap) verify() *ValidationError {
+	if len(d) == 0 {
+		return nil
+	}
+	g := d.graph()
+	var ps []Problem
+	for _, node := range g.dup {
+		ps = append(ps, Problem{Kind: DuplicateName, Node: node})
+	}
+
+	rdeps, broken := make(map[string]int, len(g.nodes)), make(map[string]token)
+	for _, node := range g.nodes {
+		for _, dep := range g.deps[node] {
+			switch {
+			case dep == node:
+				ps, broken[node] = append(ps, Problem{Kind: SelfDep, Node: node}), token{}
+			case !g.has(dep):
+				ps, broken[node] = append(ps, Problem{Kind: MissingDep, Node: node, Path: []string{dep}}), token{}
+			default:
+				rdeps[dep]++
+			}
+		}
+	}
+
+	for _, cycle := range g.cycles() {
+		ps = append(ps, Problem{Kind: Cycle, Node: cycle[0], Path: cycle})
+		for _, node := range cycle {
+			broken[node] = token{}
+		}
+	}
+
+	// unreachable runners, dfs upstream to the broken ones
+	memo := make(map[string][]string)
+	var upstream func(node string) []string
+	upstream = func(node string) []string {
+		if r, ok := memo[node]; ok {
+			return r
+		}
+		memo[node] = nil // guard cycles
+		var r []string
+		for _, dep := range g.deps[node] {
+			if dep == node || !g.has(dep) {
+				continue
+			}
+			if _, ok := broken[dep]; ok {
+				r = append(r, dep)
+				continue
+			}
+			r = append(r, upstream(dep)...)
+		}
+		slices.Sort(r)
+		memo[node] = slices.Compact(r)
+		return memo[node]
+	}
+	for _, node := range g.nodes {
+		if _, ok := broken[node]; ok {
+			continue
+		}
+		if up := upstream(node); len(up) > 0 {
+			ps = append(ps, Problem{Kind: Unreachable, Node: node, Path: up})
+		}
+	}
+
+	var anonTol []string
+	for _, fd := range d {
+		if fd.tolerant && fd.deps[0] == "" {
+			anonTol = append(anonTol, funcName(fd.f))
+		}
+	}
+	slices.Sort(anonTol)
+	for _, node := range anonTol {
+		ps = append(ps, Problem{Kind: AnonymousTolerant, Node: node})
+	}
+	for _, node := range g.nodes {
+		if _, anon := g.anon[node]; !anon && len(g.deps[node]) == 0 && rdeps[node] == 0 {
+			ps = append(ps, Problem{Kind: Unused, Node: node})
+		}
+	}
+	var quorum []Problem
+	for _, fd := range d {
+		for _, j := range fd.joins {
+			if !j.valid() {
+				quorum = append(quorum, Problem{Kind: InvalidQuorum, Node: cond(fd.deps[0] != "", fd.deps[0], funcName(fd.f)), Path: j.deps, K: j.k})
+			}
+		}
+	}
+	slices.SortStableFunc(quorum, func(a, b Problem) int { return strings.Compare(a.Node, b.Node) })
+	ps = append(ps, quorum...)
+
+	if len(ps) == 0 {
+		return nil
+	}
+	slices.SortStableFunc(ps, func(a, b Problem) int { return int(a.Kind) - int(b.Kind) })
+	return &ValidationError{Problems: ps}
+}
+
+// returns one cycle for each strongly connected component (tarjan)
+// self dependencies are excluded
+func (g *depGraph) cycles() [][]string {
+	index, low, onStk := make(map[string]int), make(map[string]int), make(map[string]bool)
+	var stk []string
+	var sccs [][]string
+	var connect func(node string)
+	connect = func(node string) {
+		index[node], low[node] = len(index), len(index)
+		stk, onStk[node] = append(stk, node), true
+		for _, dep := range g.deps[node] {
+			if !g.has(dep) {
+				continue
+			}
+			if _, ok := index[dep]; !ok {
+				connect(dep)
+				low[node] = min(low[node], low[dep])
+			} else if onStk[dep] {
+				low[node] = min(low[node], index[dep])
+			}
+		}
+		if low[node] == index[node] {
+			var scc []string
+			for {
+				x := stk[len(stk)-1]
+				stk, onStk[x] = stk[:len(stk)-1], false
+				if scc = append(scc, x); x == node {
+					break
+				}
+			}
+			if len(scc) > 1 {
+				slices.Sort(scc)
+				sccs = append(sccs, scc)
+			}
+		}
+	}
+	for _, node := range g.nodes {
+		if _, ok := index[node]; !ok {
+			connect(node)
+		}
+	}
+
+	cycles := make([][]string, 0, len(sccs))
+	for _, scc := range sccs {
+		// dfs back to the smallest node within the scc
+		start, path, seen := scc[0], []string{scc[0]}, map[string]token{scc[0]: {}}
+		var dfs func(node string) bool
+		dfs = func(node string) bool {
+			for _, dep := range g.deps[node] {
+				if dep == node {
+					continue
+				}
+				if dep == start {
+					path = append(path, dep)
+					return true
+				}
+				if _, ok := seen[dep]; ok || !slices.Contains(scc, dep) {
+					continue
+				}
+				seen[dep], path = token{}, append(path, dep)
+				if dfs(dep) {
+					return true
+				}
+				path = path[:len(path)-1]
+			}
+			return false
+		}
+		dfs(start)
+		cycles = append(cycles, path)
+	}
+	slices.SortFunc(cycles, func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+	return cycles
+}